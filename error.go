@@ -18,7 +18,9 @@ package xtrace
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/xerrors"
 )
@@ -31,6 +33,18 @@ type formatSprinter struct {
 	// Whether or not to get detailed output
 	detail         bool
 	traceFormatter TraceFormatter
+	// The context of the error being printed
+	context TraceContext
+	// Inserted between the first message and the remaining messages, if there are any
+	detailSeparator string
+	// Removed from the start of the first message, if present
+	stripPrefix string
+	// If not zero, the number of lines of source to show on either side of each file and line number reference
+	sourceLines int
+	// If not zero, the width at which the lines of detailed output are wrapped
+	frameWidth int
+	// The last message given to the sprinter, before it was formatted
+	lastRawMessage string
 }
 
 // Print takes the output of fmt.Sprint and stores it in output.
@@ -52,7 +66,41 @@ func (sprinter *formatSprinter) Detail() bool {
 
 // insertMessage inserts the given message with a normalized format.
 func (sprinter *formatSprinter) insertMessage(message string) {
-	formattedMessage := sprinter.traceFormatter.FormatTrace(sprinter.messages, message)
+	if len(sprinter.messages) == 0 {
+		message = strings.TrimPrefix(message, sprinter.stripPrefix)
+	}
+
+	isDetail := len(sprinter.messages) > 0
+	if isDetail && sprinter.detail && sprinter.frameWidth > 0 {
+		// xerrors writes the indentation of each frame line at the end of the message before it, so it must be taken
+		// into account for the first line of this message.
+		inheritedIndentation := lastLine([]string{sprinter.lastRawMessage})
+		if strings.TrimSpace(inheritedIndentation) != "" {
+			inheritedIndentation = ""
+		}
+
+		for _, wrappedMessage := range wrapFrameLines(message, sprinter.frameWidth, inheritedIndentation) {
+			sprinter.appendMessage(wrappedMessage)
+		}
+	} else {
+		sprinter.appendMessage(message)
+	}
+
+	sprinter.lastRawMessage = message
+
+	if !isDetail || !sprinter.detail || sprinter.sourceLines == 0 {
+		return
+	}
+
+	// Each line of source is inserted as a message of its own, so that formatters handle it like any other detail.
+	for _, sourceLine := range referencedSource(message, sprinter.sourceLines) {
+		sprinter.appendMessage(sourceLine + "\n")
+	}
+}
+
+// appendMessage formats the given message and stores it.
+func (sprinter *formatSprinter) appendMessage(message string) {
+	formattedMessage := formatWithContext(sprinter.traceFormatter, sprinter.context, sprinter.messages, message)
 	sprinter.messages = append(sprinter.messages, formattedMessage)
 }
 
@@ -61,24 +109,228 @@ func (sprinter *formatSprinter) output() string {
 	// Just return the message without joining
 	if len(sprinter.messages) == 1 {
 		return sprinter.messages[0]
+	} else if len(sprinter.messages) > 1 && sprinter.detailSeparator != "" {
+		return sprinter.messages[0] + sprinter.detailSeparator + strings.Join(sprinter.messages[1:], "")
 	}
 
 	return strings.Join(sprinter.messages, "")
 }
 
+// errorStringOptions holds all of the settings used to produce the output of a single error.
+type errorStringOptions struct {
+	traceFormatter TraceFormatter
+	// The context of the error being printed
+	context TraceContext
+	// Whether or not to get detailed output
+	detail bool
+	// If not nil, wraps the xerrors.Printer given to the xerrors.Formatter
+	wrapPrinter func(xerrors.Printer) xerrors.Printer
+	// Inserted between the message of the error and its detailed output
+	detailSeparator string
+	// Removed from the start of the message of the error, if present
+	stripPrefix string
+	// If not zero, the number of lines of source to show on either side of each file and line number reference
+	sourceLines int
+	// Whether or not errors that do not implement xerrors.Formatter should have the output of %+v as their detail
+	verboseFallback bool
+	// If not zero, the width at which the lines of detailed output are wrapped
+	frameWidth int
+}
+
 // generateErrorString will produce the result of the given xerrors.Formatter with/without detail, as requested.
 // If the given error does not implement xerrors.Formatter, will return err.Error() instead
-func generateErrorString(err error, traceFormatter TraceFormatter, detail bool) string {
+func generateErrorString(err error, options errorStringOptions) string {
 	formatter, isFormatter := err.(xerrors.Formatter)
-	if !isFormatter {
-		return traceFormatter.FormatTrace(nil, err.Error())
+	if !isFormatter && options.detail && options.verboseFallback {
+		return generateVerboseErrorString(err, options)
+	} else if !isFormatter {
+		message := strings.TrimPrefix(err.Error(), options.stripPrefix)
+
+		return formatWithContext(options.traceFormatter, options.context, nil, message)
 	}
 
 	sprinter := &formatSprinter{
-		detail:         detail,
-		traceFormatter: traceFormatter,
+		detail:          options.detail,
+		traceFormatter:  options.traceFormatter,
+		context:         options.context,
+		detailSeparator: options.detailSeparator,
+		stripPrefix:     options.stripPrefix,
+		sourceLines:     options.sourceLines,
+		frameWidth:      options.frameWidth,
+	}
+
+	// The location of an error produced by Errorf is known up front, so there is no need to render it to find out
+	// whether or not its detailed output adds anything.
+	frameErr, isFrameErr := formatter.(*frameError)
+	detailKnown := isFrameErr && options.wrapPrinter == nil
+	if detailKnown {
+		sprinter.detail = sprinter.detail && frameErr.hasFrame()
+	}
+
+	if !sprinter.detail || detailKnown {
+		formatter.FormatError(wrappedPrinter(sprinter, options.wrapPrinter))
+
+		return sprinter.output()
+	}
+
+	// If the detailed output would not add anything beyond the plain message, there is no point in producing it, as it
+	// will only introduce blank lines.
+	messages := renderDetail(formatter, options.wrapPrinter)
+	if !detailAddsContent(messages) {
+		sprinter.detail = false
+		messages = messages[:1]
+	}
+
+	for _, message := range messages {
+		sprinter.insertMessage(message)
+	}
+
+	return sprinter.output()
+}
+
+// generateVerboseErrorString produces the output of an error that does not implement xerrors.Formatter, using the
+// output of %+v as its detailed output. If the output of %+v starts with the message of the error, only the rest of it
+// is used, so the message is not repeated. Each line of the detailed output is given to the formatter as a message of
+// its own, as with the detailed output of an xerrors.Formatter.
+func generateVerboseErrorString(err error, options errorStringOptions) string {
+	sprinter := &formatSprinter{
+		detail:          true,
+		traceFormatter:  options.traceFormatter,
+		context:         options.context,
+		detailSeparator: options.detailSeparator,
+		stripPrefix:     options.stripPrefix,
+		sourceLines:     options.sourceLines,
+		frameWidth:      options.frameWidth,
+	}
+
+	message := err.Error()
+	sprinter.Print(message)
+	verboseOutput := fmt.Sprintf("%+v", err)
+	detail := strings.TrimPrefix(verboseOutput, message)
+	if strings.TrimSpace(detail) == "" {
+		return sprinter.output()
+	}
+
+	for _, line := range strings.Split(strings.Trim(detail, "\n"), "\n") {
+		sprinter.Print(line + "\n")
 	}
-	formatter.FormatError(sprinter)
 
 	return sprinter.output()
 }
+
+// frameLineIndentation is added to the indentation of a line of detailed output to indent the lines it is wrapped onto.
+const frameLineIndentation = "    "
+
+// wrapFrameLines wraps each line of the given detailed output that is longer than width runes, with a hanging
+// indentation. The first line is treated as if it were preceded by inheritedIndentation. Lines are broken after a path
+// separator where possible, so that file paths wrap at directory boundaries, and at exactly width runes otherwise.
+// Lines made up of only whitespace are left as is. The output is split into a message for each line it is wrapped
+// onto, each ending in the indentation of the next, as xerrors does for frames.
+func wrapFrameLines(message string, width int, inheritedIndentation string) []string {
+	wrappedMessages := []string{}
+	currentMessage := ""
+	for i, line := range strings.Split(message, "\n") {
+		if i > 0 {
+			inheritedIndentation = ""
+			currentMessage += "\n"
+		}
+
+		inheritedWidth := utf8.RuneCountInString(inheritedIndentation)
+		if inheritedWidth+utf8.RuneCountInString(line) <= width || strings.TrimSpace(line) == "" {
+			currentMessage += line
+			continue
+		}
+
+		content := strings.TrimLeft(line, " \t")
+		hangingIndentation := inheritedIndentation + line[:len(line)-len(content)] + frameLineIndentation
+		continuationWidth := width - utf8.RuneCountInString(hangingIndentation)
+		if continuationWidth < 1 {
+			continuationWidth = 1
+		}
+
+		remaining := []rune(line)
+		lineWidth := width - inheritedWidth
+		if lineWidth < 1 {
+			lineWidth = 1
+		}
+
+		for len(remaining) > lineWidth {
+			breakAt := lineWidth
+			if separatorIndex := lastPathSeparator(remaining[:lineWidth]); separatorIndex > 0 {
+				breakAt = separatorIndex + 1
+			}
+
+			wrappedLine := currentMessage + string(remaining[:breakAt])
+			wrappedMessages = append(wrappedMessages, wrappedLine+"\n"+hangingIndentation)
+			currentMessage = ""
+			remaining = remaining[breakAt:]
+			lineWidth = continuationWidth
+		}
+
+		currentMessage += string(remaining)
+	}
+
+	return append(wrappedMessages, currentMessage)
+}
+
+// lastPathSeparator finds the index of the last path separator in the given runes, or -1 if there is none.
+func lastPathSeparator(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == '/' || runes[i] == '\\' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// wrappedPrinter wraps the given sprinter with wrapPrinter, if it is not nil.
+func wrappedPrinter(sprinter *formatSprinter, wrapPrinter func(xerrors.Printer) xerrors.Printer) xerrors.Printer {
+	if wrapPrinter == nil {
+		return sprinter
+	}
+
+	return wrapPrinter(sprinter)
+}
+
+// renderDetail produces the messages printed by the given xerrors.Formatter with detailed output, as they were printed,
+// so that the output can be checked before it is given to a TraceFormatter, without calling FormatError again. There is
+// always at least one message, even if FormatError printed nothing.
+func renderDetail(formatter xerrors.Formatter, wrapPrinter func(xerrors.Printer) xerrors.Printer) []string {
+	sprinter := &formatSprinter{
+		detail:         true,
+		traceFormatter: NilFormatter{},
+	}
+	formatter.FormatError(wrappedPrinter(sprinter, wrapPrinter))
+	if len(sprinter.messages) == 0 {
+		return []string{""}
+	}
+
+	return sprinter.messages
+}
+
+// detailAddsContent checks whether or not the given messages, as produced by renderDetail, contain anything other than
+// whitespace beyond the message of the error. As the message is the first thing printed by FormatError, anything
+// printed after it is treated as the detail.
+func detailAddsContent(messages []string) bool {
+	if len(messages) < 2 {
+		return false
+	}
+
+	return strings.TrimSpace(strings.Join(messages[1:], "")) != ""
+}
+
+// plainMessage produces the message of the given error, without any detail or formatting. If the given error does not
+// implement xerrors.Formatter, will return err.Error() instead.
+func plainMessage(err error) string {
+	return generateErrorString(err, errorStringOptions{traceFormatter: NilFormatter{}})
+}
+
+// hashError produces a short, stable hash of the plain message of the given error, as eight hexadecimal characters.
+// Only the plain message is used so that the hash does not change with volatile details, such as file paths.
+func hashError(err error) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.TrimSpace(plainMessage(err))))
+
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
@@ -0,0 +1,146 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Frame holds the location that an error was created at.
+type Frame struct {
+	// Function is the fully qualified name of the function (e.g. "main.main").
+	Function string
+	// File is the full path of the file.
+	File string
+	// Line is the line number within File.
+	Line int
+}
+
+// frameError is an error produced by Errorf, which stores the location that it was created at.
+type frameError struct {
+	message string
+	// The error that this error wraps, if any
+	wrapped error
+	// Whether or not wrapped was trimmed from message, in which case it is printed as the next error in the chain
+	wrapsSuffix bool
+	frame       Frame
+}
+
+// Errorf behaves like xerrors.Errorf, formatting an error according to the format specifier and recording the location
+// it was called from. If the format ends with ": %w", the returned error wraps the final argument, and its message
+// does not include the wrapped error's message, just as with xerrors.Errorf. Otherwise, %w may be used anywhere in the
+// format, as with fmt.Errorf, and the message includes the wrapped error's message. The returned error works with
+// errors.Is, errors.As, and errors.Unwrap.
+//
+// Unlike xerrors.Errorf, the location of the error is stored in a structured form, which can be retrieved with
+// FrameOf, and so the Tracer does not need to render the detailed output of the error to find out whether or not it
+// has any. The detailed output of these errors is identical to that of the errors produced by xerrors.Errorf, so the
+// two may be freely mixed within the same chain.
+func Errorf(format string, args ...interface{}) error {
+	err := &frameError{frame: callerFrame()}
+	wrapped, isError := lastArg(args).(error)
+	if strings.HasSuffix(format, ": %w") && isError {
+		err.message = fmt.Sprintf(strings.TrimSuffix(format, ": %w"), args[:len(args)-1]...)
+		err.wrapped = wrapped
+		err.wrapsSuffix = true
+
+		return err
+	}
+
+	formatted := fmt.Errorf(format, args...)
+	err.message = formatted.Error()
+	err.wrapped = xerrors.Unwrap(formatted)
+
+	return err
+}
+
+// FrameOf returns the location that the given error was created at, if it was produced by Errorf. Only the given
+// error is checked; the errors it wraps are not.
+func FrameOf(err error) (Frame, bool) {
+	frameErr, isFrameErr := err.(*frameError)
+	if !isFrameErr {
+		return Frame{}, false
+	}
+
+	return frameErr.frame, true
+}
+
+// callerFrame finds the location of the caller of the function that called it.
+func callerFrame() Frame {
+	programCounters := make([]uintptr, 1)
+	// Skip runtime.Callers, callerFrame, and its caller.
+	if runtime.Callers(3, programCounters) == 0 {
+		return Frame{}
+	}
+
+	frame, _ := runtime.CallersFrames(programCounters).Next()
+
+	return Frame{Function: frame.Function, File: frame.File, Line: frame.Line}
+}
+
+// lastArg gets the last of the given arguments, or nil if there are none.
+func lastArg(args []interface{}) interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+
+	return args[len(args)-1]
+}
+
+// Error implements the error interface.
+func (err *frameError) Error() string {
+	if err.wrapsSuffix {
+		return err.message + ": " + err.wrapped.Error()
+	}
+
+	return err.message
+}
+
+// Unwrap returns the error that this error wraps, if any.
+func (err *frameError) Unwrap() error {
+	return err.wrapped
+}
+
+// Format implements fmt.Formatter, so that detailed output is produced with %+v, as with the errors produced by
+// xerrors.Errorf.
+func (err *frameError) Format(s fmt.State, verb rune) {
+	xerrors.FormatError(err, s, verb)
+}
+
+// FormatError implements xerrors.Formatter, printing the location of the error in the same form as xerrors.Frame.
+func (err *frameError) FormatError(printer xerrors.Printer) error {
+	printer.Print(err.message)
+	if printer.Detail() && err.hasFrame() {
+		printer.Printf("%s\n    ", err.frame.Function)
+		printer.Printf("%s:%d\n", err.frame.File, err.frame.Line)
+	}
+
+	if !err.wrapsSuffix {
+		return nil
+	}
+
+	return err.wrapped
+}
+
+// hasFrame checks whether or not the location of the error is known.
+func (err *frameError) hasFrame() bool {
+	return err.frame.File != ""
+}
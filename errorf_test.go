@@ -0,0 +1,111 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestErrorf(t *testing.T) {
+	baseErr := errors.New("things broke :(")
+	tests := []struct {
+		name            string
+		err             error
+		expectedMessage string
+		expectedWrapped error
+	}{
+		{
+			name:            "no wrapping",
+			err:             Errorf("aw shucks, %d things broke", 2),
+			expectedMessage: "aw shucks, 2 things broke",
+			expectedWrapped: nil,
+		},
+		{
+			name:            "wrapping suffix",
+			err:             Errorf("aw shucks: %w", baseErr),
+			expectedMessage: "aw shucks: things broke :(",
+			expectedWrapped: baseErr,
+		},
+		{
+			name:            "wrapping elsewhere",
+			err:             Errorf("%w, aw shucks", baseErr),
+			expectedMessage: "things broke :(, aw shucks",
+			expectedWrapped: baseErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedMessage, tt.err.Error())
+			assert.Equal(t, tt.expectedWrapped, errors.Unwrap(tt.err))
+			if tt.expectedWrapped != nil {
+				assert.True(t, errors.Is(tt.err, baseErr))
+			}
+		})
+	}
+}
+
+func TestErrorf_As(t *testing.T) {
+	err := Errorf("aw shucks: %w", tracerTestError{message: "things broke :("})
+
+	var target tracerTestError
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, "things broke :(", target.message)
+}
+
+func TestFrameOf(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	err := Errorf("things broke :(")
+
+	frame, hasFrame := FrameOf(err)
+	assert.True(t, hasFrame)
+	assert.Equal(t, file, frame.File)
+	assert.Equal(t, line+1, frame.Line)
+	assert.Equal(t, "github.com/ollien/xtrace.TestFrameOf", frame.Function)
+
+	_, hasFrame = FrameOf(xerrors.Errorf("things broke :("))
+	assert.False(t, hasFrame)
+}
+
+func TestErrorf_Trace(t *testing.T) {
+	err := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err)
+	err3 := Errorf("I tried very hard and failed: %w", err2)
+	tracer, constructErr := NewTracer(err3, Formatter(NewNilFormatter()))
+	assert.Nil(t, constructErr)
+
+	messages := []string{}
+	traceErr := tracer.TraceFunc(func(index int, message string) {
+		messages = append(messages, message)
+	})
+	assert.Nil(t, traceErr)
+	assert.Len(t, messages, 3)
+
+	// Both the xerrors error and the Errorf error must be rendered the same way.
+	detailPattern := `github\.com/ollien/xtrace\.TestErrorf_Trace\n    \S+/errorf_test\.go:\d+\n$`
+	assert.Regexp(t, "^aw shucks"+detailPattern, messages[1])
+	assert.Regexp(t, "^I tried very hard and failed"+detailPattern, messages[2])
+
+	assert.Equal(t, "I tried very hard and failed: aw shucks: things broke :(", fmt.Sprintf("%v", err3))
+	assert.Contains(t, fmt.Sprintf("%+v", err3), "errorf_test.go")
+}
@@ -0,0 +1,74 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// TracerFactory makes Tracers that all share the same options, such as for a server that traces many errors in the
+// same way. The options are applied once, to a template Tracer, when the factory is made, so that a misconfiguration is
+// found up front, and each Tracer is made by copying the template, rather than by applying the options again.
+//
+// Stateful formatters still get a fresh instance for each Tracer: a formatter given with FormatterFunc is made anew by
+// calling its factory again, and one given with Formatter is cloned if it is Cloneable, and shared otherwise. Each
+// Tracer is given its own buffer for Read, so a buffer given with the Buffer option is never used. A TracerFactory
+// holds no state of its own once it is made, so it is safe to use from multiple goroutines at once.
+type TracerFactory struct {
+	template *Tracer
+}
+
+// NewTracerFactory makes a new TracerFactory that makes Tracers with the given options. Returns an error if the options
+// can not be applied to a Tracer.
+func NewTracerFactory(options ...func(*Tracer) error) (*TracerFactory, error) {
+	template, err := newTracer(nil, options...)
+	if err != nil {
+		return nil, xerrors.Errorf("Could not construct TracerFactory: %w", err)
+	}
+
+	return &TracerFactory{template: template}, nil
+}
+
+// New returns a new Tracer for the given error, with the options of the factory. Returns an error if a fresh formatter
+// could not be made with the factory given to FormatterFunc.
+func (factory *TracerFactory) New(baseErr error) (*Tracer, error) {
+	tracer := *factory.template
+	tracer.baseErr = baseErr
+	tracer.baseErrs = []error{baseErr}
+	tracer.buffer = bytes.NewBuffer([]byte{})
+	if tracer.formatterFactory != nil {
+		formatter, err := tracer.formatterFactory()
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct Tracer: could not construct formatter: %w", err)
+		}
+
+		tracer.formatter = formatter
+	} else {
+		tracer.formatter = cloneFormatter(factory.template.formatter)
+	}
+
+	if _, isUnsynchronized := tracer.readMux.(noopLocker); !isUnsynchronized {
+		tracer.readMux = &sync.Mutex{}
+	}
+
+	tracer.rebuildChain()
+
+	return &tracer, nil
+}
@@ -0,0 +1,183 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestTracerFactory(t *testing.T) {
+	factory, err := NewTracerFactory(
+		DetailedOutput(false),
+		Ordering(NewestFirstOrdering),
+		FormatterFunc(func() (TraceFormatter, error) {
+			return NewNewLineFormatter()
+		}),
+	)
+	assert.Nil(t, err)
+
+	waitGroup := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+
+			baseErr := xerrors.Errorf("aw shucks %d: %w", i, errors.New("things broke :("))
+			tracer, err := factory.New(baseErr)
+			assert.Nil(t, err)
+			assert.Equal(t, fmt.Sprintf("aw shucks %d\nthings broke :(", i), fmt.Sprintf("%v", tracer))
+		}(i)
+	}
+
+	waitGroup.Wait()
+}
+
+// countingFormatter is a stateful TraceFormatter that is not Cloneable, which numbers each message it formats.
+type countingFormatter struct {
+	count int
+}
+
+func (formatter *countingFormatter) FormatTrace(previousMessages []string, message string) string {
+	formatter.count++
+
+	return fmt.Sprintf("%d:%s", formatter.count, message)
+}
+
+func TestTracerFactory_FreshFormatters(t *testing.T) {
+	factory, err := NewTracerFactory(DetailedOutput(false), FormatterFunc(func() (TraceFormatter, error) {
+		return &countingFormatter{}, nil
+	}))
+	assert.Nil(t, err)
+
+	tracer1, err := factory.New(errors.New("a"))
+	assert.Nil(t, err)
+	tracer2, err := factory.New(errors.New("b"))
+	assert.Nil(t, err)
+
+	assert.True(t, tracer1.formatter != tracer2.formatter)
+	// Each Tracer's formatter must start counting from the start.
+	for _, tracer := range []*Tracer{tracer1, tracer2} {
+		message, err := tracer.ReadNext()
+		assert.Nil(t, err)
+		assert.Equal(t, "1:"+tracer.BaseError().Error(), message)
+	}
+}
+
+func TestTracerFactory_FormatterFuncError(t *testing.T) {
+	calls := 0
+	factory, err := NewTracerFactory(FormatterFunc(func() (TraceFormatter, error) {
+		calls++
+		if calls > 1 {
+			return nil, errors.New("out of formatters")
+		}
+
+		return NewNewLineFormatter()
+	}))
+	assert.Nil(t, err)
+
+	tracer, err := factory.New(errors.New("things broke :("))
+	assert.NotNil(t, err)
+	assert.Nil(t, tracer)
+}
+
+func TestTracerFactory_StatefulFormatter(t *testing.T) {
+	factory, err := NewTracerFactory(DetailedOutput(false), Formatter(NewMarkdownOrderedFormatter()))
+	assert.Nil(t, err)
+
+	// Each Tracer must be numbered from the start, as the formatter is cloned for each of them.
+	tracer1, err := factory.New(xerrors.Errorf("aw shucks: %w", errors.New("things broke")))
+	assert.Nil(t, err)
+	message, err := tracer1.ReadNext()
+	assert.Nil(t, err)
+	assert.Equal(t, "1. things broke", message)
+
+	tracer2, err := factory.New(errors.New("oh no"))
+	assert.Nil(t, err)
+	message, err = tracer2.ReadNext()
+	assert.Nil(t, err)
+	assert.Equal(t, "1. oh no", message)
+}
+
+func TestTracerFactory_Buffer(t *testing.T) {
+	buffer := bytes.NewBufferString("")
+	factory, err := NewTracerFactory(DetailedOutput(false), Buffer(buffer))
+	assert.Nil(t, err)
+
+	tracer1, err := factory.New(errors.New("first error message"))
+	assert.Nil(t, err)
+	assert.True(t, tracer1.buffer != buffer)
+
+	// Leaving part of the first error unread must not leak into the next Tracer.
+	output := make([]byte, 3)
+	_, err = tracer1.Read(output)
+	assert.Nil(t, err)
+
+	tracer2, err := factory.New(errors.New("second error message"))
+	assert.Nil(t, err)
+	output = make([]byte, len("second error message"))
+	n, err := tracer2.Read(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "second error message", string(output[:n]))
+}
+
+func TestTracerFactory_InvalidOptions(t *testing.T) {
+	factory, err := NewTracerFactory(Ordering(NewestFirstOrdering), StableOrderingFunc(func(error) int { return 0 }))
+	assert.NotNil(t, err)
+	assert.Nil(t, factory)
+}
+
+func BenchmarkTracerFactory(b *testing.B) {
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	// The options that a server would typically trace all of its errors with, which are costly to apply to each Tracer.
+	options := []func(*Tracer) error{
+		DetailedOutput(false),
+		Ordering(NewestFirstOrdering),
+		WithField("service", "api"),
+		WithField("region", "us-east-1"),
+		WithMessages(Messages{Truncated: "...", Count: "%d errors", CountOne: "1 error"}),
+		FormatterFunc(func() (TraceFormatter, error) {
+			nested, err := NewNestedMessageFormatter(NestingIndentation("  "))
+			if err != nil {
+				return nil, err
+			}
+
+			return NewBulletFormatter(BulletInnerFormatter(nested))
+		}),
+	}
+
+	b.Run("NewTracer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = NewTracer(baseErr, options...)
+		}
+	})
+
+	b.Run("TracerFactory", func(b *testing.B) {
+		factory, _ := NewTracerFactory(options...)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = factory.New(baseErr)
+		}
+	})
+}
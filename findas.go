@@ -0,0 +1,45 @@
+//go:build go1.21
+
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// FindAs finds the first error in the Tracer's chain, starting from the most recent error, that can be assigned to T,
+// and returns it. If no error in the chain matches, false is returned. For a Tracer constructed with NewMultiTracer,
+// each top-level error's chain is searched in turn. The state of the Tracer is not modified.
+//
+// Only the errors that the Tracer would trace are searched, so the chain follows Cause where PreferCause is set, and
+// ends where StopAt or SinceBase dictate. An error matches if it is of type T, or if its As method reports that it
+// matches, as with errors.As; however, unlike errors.As, the errors that it wraps are not considered, as they are
+// searched in turn if they are in the chain. This is only available with Go 1.21 and newer.
+func FindAs[T error](tracer *Tracer) (T, bool) {
+	var target T
+	for _, baseErr := range tracer.baseErrs {
+		for _, err := range tracer.chainOf(baseErr) {
+			if matched, isMatch := err.(T); isMatch {
+				return matched, true
+			}
+
+			asErr, hasAs := err.(interface{ As(interface{}) bool })
+			if hasAs && asErr.As(&target) {
+				return target, true
+			}
+		}
+	}
+
+	return target, false
+}
@@ -0,0 +1,122 @@
+//go:build go1.21
+
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+type findAsTestError struct {
+	code int
+}
+
+func (err findAsTestError) Error() string {
+	return "things broke :("
+}
+
+type otherFindAsTestError struct{}
+
+func (err *otherFindAsTestError) Error() string {
+	return "aw shucks"
+}
+
+func TestFindAs(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "matching errors",
+			setup: func(t *testing.T) *Tracer {
+				err := findAsTestError{code: 42}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewMultiTracer([]error{errors.New("unrelated"), err3, &otherFindAsTestError{}})
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				found, ok := FindAs[findAsTestError](tracer)
+				assert.True(t, ok)
+				assert.Equal(t, 42, found.code)
+
+				other, ok := FindAs[*otherFindAsTestError](tracer)
+				assert.True(t, ok)
+				assert.NotNil(t, other)
+			},
+		},
+		{
+			name: "no matching errors",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				found, ok := FindAs[findAsTestError](tracer)
+				assert.False(t, ok)
+				assert.Equal(t, findAsTestError{}, found)
+
+				_, ok = FindAs[*otherFindAsTestError](tracer)
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "matching error past StopAt",
+			setup: func(t *testing.T) *Tracer {
+				err := findAsTestError{code: 42}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				stopAt := func(err error) bool {
+					return err.Error() == "aw shucks: things broke :("
+				}
+				tracer, constructErr := NewTracer(err2, StopAt(stopAt, true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, ok := FindAs[findAsTestError](tracer)
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "matching error below SinceBase",
+			setup: func(t *testing.T) *Tracer {
+				err := findAsTestError{code: 42}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3)
+				if constructErr != nil {
+					return handleTracerTestSetupError(t, tracer, constructErr)
+				}
+
+				subTracer, constructErr := tracer.SinceBase(err2)
+
+				return handleTracerTestSetupError(t, subTracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, ok := FindAs[findAsTestError](tracer)
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
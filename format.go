@@ -17,8 +17,17 @@ package xtrace
 */
 
 import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/xerrors"
 )
@@ -30,6 +39,147 @@ type TraceFormatter interface {
 	FormatTrace(previousMessages []string, message string) string
 }
 
+// Dropped may be returned by a TraceFormatter in place of a formatted message to drop the error that the message belongs
+// to from the trace entirely. This is distinct from returning an empty message: if all of an error's messages are
+// empty, the error is still outputted, with a placeholder in place of its message, whereas a dropped error is skipped
+// as if it were not in the chain at all. Dropped errors are still counted towards the Index of the errors after them.
+const Dropped = "\x00xtrace:dropped\x00"
+
+// Resettable is a TraceFormatter that holds state between calls to FormatTrace, which can be cleared. If a Tracer's
+// formatter implements this interface, Reset will be called at the start of each full trace (i.e. by Tracer.Trace and
+// Tracer.Format), allowing the formatter to be reused between traces.
+type Resettable interface {
+	// Reset clears any state held by the formatter, so it may be used for a new trace.
+	Reset()
+}
+
+// Cloneable is a TraceFormatter that can produce an independent copy of itself, with the same configuration but none of
+// its state. Stateless formatters, such as NilFormatter, NestedMessageFormatter and DOTFormatter, may be freely shared
+// between Tracers. Stateful formatters, such as NewLineFormatter, must not be shared between Tracers that are read
+// concurrently, but a Cloneable formatter can be cloned to cheaply obtain a fresh copy for each Tracer, such as when
+// pooling formatters. All of the stateful formatters in this package are Cloneable, which Tracer.Root and Tracer.Top
+// rely on to format a single error without disturbing the state of the Tracer's formatter.
+type Cloneable interface {
+	// Clone returns a new formatter with the same configuration as this one, sharing no state with it.
+	Clone() TraceFormatter
+}
+
+// cloneFormatter produces a clone of the given formatter if it is Cloneable. Otherwise, the formatter itself is
+// returned, as there is no way to copy it.
+func cloneFormatter(formatter TraceFormatter) TraceFormatter {
+	if cloneable, isCloneable := formatter.(Cloneable); isCloneable {
+		return cloneable.Clone()
+	}
+
+	return formatter
+}
+
+// TraceContext holds information about the position of the error currently being formatted within the trace.
+//
+// There are two conventions for the position of an error. Depth is anchored at the root cause, which always has a depth
+// of zero, with each wrapping error having a depth one greater than the error it wraps; this does not change based on
+// the Tracer's ordering. Index is anchored at the start of the output, so the first error that is outputted has an
+// index of zero, regardless of where it is in the chain. With OldestFirstOrdering, these two values are identical.
+type TraceContext struct {
+	// Depth is the position of the error within the chain, where the root cause has a depth of zero.
+	Depth int
+	// Index is the position of the error within the output, where the first error outputted has an index of zero.
+	Index int
+	// ChainLength is the number of errors in the chain that this error belongs to, so the error that wraps all others
+	// has a depth of ChainLength - 1.
+	ChainLength int
+	// Group is the position of the top-level error that this error belongs to, for Tracers constructed with
+	// NewMultiTracer. For all other Tracers, this is always zero.
+	Group int
+	// Last indicates whether or not this is the last error that will be outputted in the trace.
+	Last bool
+	// Err is the error being formatted, which allows formatters to inspect the error itself.
+	Err error
+	// Chain holds all of the errors in the chain that Err belongs to, from the error that wraps all others to the root
+	// cause, regardless of the Tracer's ordering, which allows formatters to inspect the whole chain. It must not be
+	// modified.
+	Chain []error
+	// Fields holds the fields attached to the Tracer with WithField, or nil if there are none. It must not be modified.
+	Fields map[string]interface{}
+	// Wrapper is the error that wraps Err within its chain, or nil if Err wraps all others, which allows formatters to
+	// inspect the error that is outputted after Err with OldestFirstOrdering, before it is outputted.
+	Wrapper error
+	// Messages holds the user-facing strings of the Tracer, as set with WithMessages, which allows formatters to write
+	// the same localized strings as the Tracer.
+	Messages Messages
+}
+
+// IsRoot checks whether or not the error is the root cause of its chain, i.e. the deepest error, which wraps no others.
+func (context TraceContext) IsRoot() bool {
+	return context.Depth == 0
+}
+
+// ContextualTraceFormatter is a TraceFormatter that also makes use of the position of the error being formatted. If a
+// Tracer's formatter implements this interface, FormatTraceWithContext will be called in place of FormatTrace.
+type ContextualTraceFormatter interface {
+	TraceFormatter
+	// FormatTraceWithContext is identical to FormatTrace, but is also given the TraceContext of the error that the
+	// message belongs to.
+	FormatTraceWithContext(context TraceContext, previousMessages []string, message string) string
+}
+
+// FieldsFormatter is a TraceFormatter that also controls how the fields attached to a Tracer with WithField are
+// written, once, at the start of each full trace. If a Tracer's formatter does not implement this interface, the fields
+// are written as a single line of logfmt-style pairs (e.g. "request_id=abc123 user=nick"), sorted by key.
+type FieldsFormatter interface {
+	TraceFormatter
+	// FormatFields produces the header that holds the given fields, without a trailing newline. If it returns "", no
+	// header is written. The given map must not be modified.
+	FormatFields(fields map[string]interface{}) string
+}
+
+// LineEndingFormatter is a TraceFormatter that writes the line endings in its messages as something other than "\n". A
+// Tracer whose formatter implements this interface writes every line ending of a full trace with the same line ending,
+// including the newlines that it writes between errors itself, so that the output does not mix line endings.
+type LineEndingFormatter interface {
+	TraceFormatter
+	// LineEnding gets the line ending that the formatter writes, which is either "\n" or "\r\n".
+	LineEnding() string
+}
+
+// formatFieldsLogfmt formats the given fields as logfmt-style pairs, sorted by key. Values are formatted with
+// fmt.Sprint, and quoted if they are empty or contain whitespace, quotes, or equals signs.
+func formatFieldsLogfmt(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+
+		pairs[i] = key + "=" + value
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// formatWithContext will format the given message with the given formatter, passing the given context if the formatter
+// is a ContextualTraceFormatter.
+func formatWithContext(
+	formatter TraceFormatter,
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	contextualFormatter, isContextual := formatter.(ContextualTraceFormatter)
+	if !isContextual {
+		return formatter.FormatTrace(previousMessages, message)
+	}
+
+	return contextualFormatter.FormatTraceWithContext(context, previousMessages, message)
+}
+
 // NilFormatter applies no formatting and returns the given message as xerrors sends them.
 // Note that the messages that xerrors sends aren't always the most intuitive (e.g. there are no newlines after error
 // messages), and the usage of this formatter is not strictly recommended. It is mainly provided for those that want
@@ -48,9 +198,15 @@ func (formatter NilFormatter) FormatTrace(previousMessages []string, message str
 
 // NestedMessageFormatter will leave the leading line with no indentation, but indents all lines following, stripping
 // whitespace from the both the left and right of each line and replacing it with a newline, unless it is the last
-// message. In this case, no newline is inserted, but whitespace is still stripped.
+// message. In this case, no newline is inserted, but whitespace is still stripped. Messages that span multiple lines,
+// such as detailed output that holds both a function and its file and line number, have every line indented in this
+// way, not just the first.
 type NestedMessageFormatter struct {
 	indentation string
+	// Whether or not each error should be indented based on its depth. See the IndentByDepth method for more info
+	indentByDepth bool
+	// If set, computes the indentation of each line in place of indentation
+	indentFunc func(previous string) string
 }
 
 // NewNestedMessageFormatter makes a new NestedMessageFormatter.
@@ -66,18 +222,40 @@ func NewNestedMessageFormatter(options ...func(*NestedMessageFormatter) error) (
 	return formatter, nil
 }
 
+// Reset implements Resettable. NestedMessageFormatter holds no state between messages, so this does nothing.
+func (formatter NestedMessageFormatter) Reset() {}
+
+// Clone implements Cloneable, returning a copy of the formatter with the same indentation.
+func (formatter NestedMessageFormatter) Clone() TraceFormatter {
+	clone := formatter
+
+	return &clone
+}
+
 // FormatTrace formats the message as dictated by the contract for NestedMessageFormatter.
 func (formatter NestedMessageFormatter) FormatTrace(previousMessages []string, message string) string {
-	formattedMessage := strings.TrimSpace(message)
-	// All messages except the first must begin with the given indentation, so if we have the first, we're done.
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	previousLine := lastLine(previousMessages)
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+		// All lines except the very first line of the error must begin with the given indentation.
+		if (i != 0 || len(previousMessages) != 0) && len(lines[i]) > 0 {
+			lines[i] = formatter.lineIndentation(previousLine) + lines[i]
+		}
+
+		if len(lines[i]) > 0 {
+			previousLine = lines[i]
+		}
+	}
+
+	formattedMessage := strings.Join(lines, "\n")
 	if len(previousMessages) == 0 {
 		return formattedMessage
 	}
 
-	formattedMessage = formatter.indentation + formattedMessage
 	lastMessage := previousMessages[len(previousMessages)-1]
 	// Make sure the previous message ends with a newline
-	if lastMessage[len(lastMessage)-1] != '\n' {
+	if len(lastMessage) > 0 && lastMessage[len(lastMessage)-1] != '\n' {
 		lastMessage += "\n"
 		previousMessages[len(previousMessages)-1] = lastMessage
 	}
@@ -85,17 +263,81 @@ func (formatter NestedMessageFormatter) FormatTrace(previousMessages []string, m
 	return formattedMessage
 }
 
+// FormatTraceWithContext formats the message as dictated by the contract for NestedMessageFormatter. If
+// IndentByDepth is set, every line of the message is further indented once for each error between it and the error
+// that wraps all others in its chain.
+func (formatter NestedMessageFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatter.FormatTrace(previousMessages, message)
+	if !formatter.indentByDepth {
+		return formattedMessage
+	}
+
+	depthIndentation := strings.Repeat(formatter.indentation, context.ChainLength-context.Depth-1)
+	lines := strings.Split(formattedMessage, "\n")
+	for i, line := range lines {
+		if len(line) > 0 {
+			lines[i] = depthIndentation + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// lineEndingReplacer normalizes "\r\n" and lone "\r" line endings to "\n".
+var lineEndingReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// whitespaceRunPattern matches a run of spaces and tabs.
+var whitespaceRunPattern = regexp.MustCompile(`[ \t]+`)
+
+// lineIndentation produces the indentation for a line, given the formatted line before it.
+func (formatter NestedMessageFormatter) lineIndentation(previousLine string) string {
+	if formatter.indentFunc == nil {
+		return formatter.indentation
+	}
+
+	return formatter.indentFunc(previousLine)
+}
+
+// lastLine gets the last line of the last of the given messages, without its newline, or "" if there are no messages.
+func lastLine(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+
+	message := strings.TrimRight(messages[len(messages)-1], "\n")
+
+	return message[strings.LastIndex(message, "\n")+1:]
+}
+
+// blankLinesPattern matches a run of more than one blank line, including the newline that ends the line before it.
+var blankLinesPattern = regexp.MustCompile(`\n(?:[ \t]*\n){2,}`)
+
 // NewLineFormatter ensures that all messages except the last end in a newline after all error content.
 type NewLineFormatter struct {
-	// naive will enable the naive algorithm. See the Naive method for more info
-	naive bool
+	// trimStrategy is the algorithm used to strip and restore newlines. See the TrimStrategy method for more info
+	trimStrategy TrimStrategyMethod
+	// collapseBlankLines will squeeze runs of blank lines into one. See the CollapseBlankLines method for more info
+	collapseBlankLines bool
+	// preserveIndent will keep every line of a message that spans multiple lines when stripping newlines. See the
+	// PreserveIndent method for more info
+	preserveIndent bool
+	// normalizeWhitespace will collapse runs of spaces and tabs within each line. See the NormalizeWhitespace method
+	// for more info
+	normalizeWhitespace bool
+	// lineEnding is the line ending that all line endings in the output are written as. See the LineEnding method for
+	// more info
+	lineEnding string
 	// holds the last message with no newline stripped
 	lastRawMessage string
 }
 
 // NewNewLineFormatter will make a new NewLineFormatter.
 func NewNewLineFormatter(options ...func(*NewLineFormatter) error) (*NewLineFormatter, error) {
-	formatter := &NewLineFormatter{naive: false}
+	formatter := &NewLineFormatter{trimStrategy: TrimRegex, lineEnding: "\n"}
 	for _, optionFunc := range options {
 		err := optionFunc(formatter)
 		if err != nil {
@@ -106,30 +348,101 @@ func NewNewLineFormatter(options ...func(*NewLineFormatter) error) (*NewLineForm
 	return formatter, nil
 }
 
+// Reset implements Resettable, discarding the last message that the formatter has seen.
+func (formatter *NewLineFormatter) Reset() {
+	formatter.lastRawMessage = ""
+}
+
+// Clone implements Cloneable, returning a copy of the formatter with the same options, but without the last message
+// that this formatter has seen.
+func (formatter *NewLineFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.lastRawMessage = ""
+
+	return &clone
+}
+
+// SetNaive sets the naive flag of the formatter, as the Naive option does, allowing the algorithm to be changed between
+// traces without constructing a new formatter. As the formatter holds the last message it has seen to terminate it
+// later, changing the flag in the middle of a trace gives undefined results; it should only be changed before a trace
+// begins, or after the formatter is reset.
+func (formatter *NewLineFormatter) SetNaive(naive bool) {
+	formatter.trimStrategy = trimStrategyForNaive(naive)
+}
+
 // FormatTrace formats the message as dictated by the contract for NewLineFormatter.
 func (formatter *NewLineFormatter) FormatTrace(previousMessages []string, message string) (formatted string) {
+	// All line endings are normalized to "\n" up front, so that the rest of the algorithm only needs to consider "\n".
+	message = lineEndingReplacer.Replace(message)
+	if formatter.normalizeWhitespace {
+		message = normalizeInternalWhitespace(message)
+	}
+
+	if formatter.collapseBlankLines {
+		message = blankLinesPattern.ReplaceAllString(message, "\n\n")
+	}
+
 	lastMessage := formatter.lastRawMessage
 	formatter.lastRawMessage = message
-	formatted = formatter.stripNewlines(message)
+	formatted = formatter.withLineEnding(formatter.stripNewlines(message))
 	if len(previousMessages) == 0 {
 		return
 	}
 
 	// Add a newline back to the last message
 	terminatedLastMessage := formatter.newLineTerminateMessage(lastMessage)
-	previousMessages[len(previousMessages)-1] = terminatedLastMessage
+	previousMessages[len(previousMessages)-1] = formatter.withLineEnding(terminatedLastMessage)
 
 	return
 }
 
+// normalizeInternalWhitespace collapses each run of spaces and tabs within each line of the message to a single space.
+// Whitespace at the start and end of each line is left as is, so that indentation is preserved.
+func normalizeInternalWhitespace(message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " \t")
+		indentation := line[:len(line)-len(content)]
+		trimmedContent := strings.TrimRight(content, " \t")
+		trailingWhitespace := content[len(trimmedContent):]
+		lines[i] = indentation + whitespaceRunPattern.ReplaceAllString(trimmedContent, " ") + trailingWhitespace
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// LineEnding implements LineEndingFormatter, getting the line ending set by the LineEnding option.
+func (formatter *NewLineFormatter) LineEnding() string {
+	if formatter.lineEnding == "" {
+		return "\n"
+	}
+
+	return formatter.lineEnding
+}
+
+// withLineEnding will replace all of the "\n" line endings in the message with the formatter's line ending.
+func (formatter *NewLineFormatter) withLineEnding(message string) string {
+	if formatter.lineEnding == "" || formatter.lineEnding == "\n" {
+		return message
+	}
+
+	return strings.Replace(message, "\n", formatter.lineEnding, -1)
+}
+
 // stripNewLines will strip new lines from the message using the given strategy.
 func (formatter *NewLineFormatter) stripNewlines(message string) string {
-	if formatter.naive {
+	if formatter.trimStrategy == TrimNone {
+		return message
+	} else if formatter.trimStrategy == TrimSimple {
 		return strings.TrimRight(message, "\n")
 	}
 
 	// Capture group will only match the trailing whitespace portion of the string
 	pattern := regexp.MustCompile(`.*\S(\s*)`)
+	if formatter.preserveIndent {
+		// Letting the content span lines keeps the lines after the first, along with their indentation, intact
+		pattern = regexp.MustCompile(`(?s).*\S(\s*)`)
+	}
 	matchBoundaries := pattern.FindStringSubmatchIndex(message)
 	// If we don't match, we don't need to strip anything
 	if matchBoundaries == nil {
@@ -148,10 +461,1559 @@ func (formatter *NewLineFormatter) stripNewlines(message string) string {
 func (formatter *NewLineFormatter) newLineTerminateMessage(message string) string {
 	pattern := regexp.MustCompile(`\s*\n\s*$`)
 	// Make sure the previous message ends with a newline, or there is newline within a trailing whitespace region.
-	if (formatter.naive && message[len(message)-1] == '\n') ||
-		(!formatter.naive && pattern.MatchString(message)) {
+	if (formatter.trimStrategy != TrimRegex && strings.HasSuffix(message, "\n")) ||
+		(formatter.trimStrategy == TrimRegex && pattern.MatchString(message)) {
 		return message
 	}
 
 	return message + "\n"
 }
+
+// GlobalDedupeFormatter looks for errors whose messages have already been seen earlier in the trace, compared
+// case-insensitively, and either annotates or suppresses them. By default, repeated messages are annotated with
+// " (repeated)", which is inserted before any whitespace that the message ends with. Only the first message of each
+// error is considered, so the detailed output of an error, such as its frame, is never annotated. If repeated messages
+// are suppressed, a repeated error is dropped from the trace entirely, detailed output and all, as with Dropped. The
+// messages seen so far are held until the formatter is reset, so two Tracers that trace at the same time with the same
+// GlobalDedupeFormatter would mark each other's messages as repeats.
+type GlobalDedupeFormatter struct {
+	// suppress will remove repeated messages entirely, rather than annotating them
+	suppress bool
+	// holds every message that has been formatted so far
+	seenMessages []string
+	// whether or not the error currently being formatted was suppressed, along with its detailed output
+	suppressingError bool
+}
+
+// NewGlobalDedupeFormatter makes a new GlobalDedupeFormatter.
+func NewGlobalDedupeFormatter(options ...func(*GlobalDedupeFormatter) error) (*GlobalDedupeFormatter, error) {
+	formatter := &GlobalDedupeFormatter{suppress: false}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct GlobalDedupeFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable, discarding all of the messages that the formatter has seen.
+func (formatter *GlobalDedupeFormatter) Reset() {
+	formatter.seenMessages = nil
+	formatter.suppressingError = false
+}
+
+// Clone implements Cloneable, returning a GlobalDedupeFormatter that suppresses or annotates repeats as this one does,
+// but has seen no messages yet.
+func (formatter *GlobalDedupeFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.seenMessages = nil
+	clone.suppressingError = false
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for GlobalDedupeFormatter.
+func (formatter *GlobalDedupeFormatter) FormatTrace(previousMessages []string, message string) string {
+	if len(previousMessages) != 0 && formatter.suppressingError {
+		return Dropped
+	} else if len(previousMessages) != 0 {
+		return message
+	}
+
+	formatter.suppressingError = false
+	content := strings.TrimRightFunc(message, unicode.IsSpace)
+	if !formatter.hasSeen(content) {
+		formatter.seenMessages = append(formatter.seenMessages, strings.TrimSpace(content))
+		return message
+	} else if formatter.suppress {
+		formatter.suppressingError = true
+		return Dropped
+	}
+
+	return content + " (repeated)" + message[len(content):]
+}
+
+// hasSeen checks if the given message has been formatted before, ignoring case.
+func (formatter *GlobalDedupeFormatter) hasSeen(message string) bool {
+	message = strings.TrimSpace(message)
+	for _, seenMessage := range formatter.seenMessages {
+		if strings.EqualFold(seenMessage, message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrimWrappedPrefixFormatter removes the portion of each error's message that repeats the message of the error it
+// wraps, leaving only the context that was added. For instance, if the error "things broke" is wrapped as
+// "aw shucks: things broke", the wrapping error will be outputted as "aw shucks". This is mainly useful for errors that
+// are wrapped with fmt.Errorf, as xerrors.Errorf already removes the wrapped message.
+//
+// The heuristic is as follows: if the first message of an error ends with ": " followed by the full first message of
+// the error before it, that portion is removed. Otherwise, the message is left as is. An error whose message is
+// identical to the previous error's message is also left as is, as trimming it would leave nothing. Because the inner
+// error must be seen before the error that wraps it, this only has an effect with OldestFirstOrdering. The message of
+// the previous error is held between calls, so a TrimWrappedPrefixFormatter must only be used by one trace at a time.
+type TrimWrappedPrefixFormatter struct {
+	// holds the untrimmed first message of the last error
+	lastRawMessage string
+}
+
+// NewTrimWrappedPrefixFormatter makes a new TrimWrappedPrefixFormatter.
+func NewTrimWrappedPrefixFormatter() *TrimWrappedPrefixFormatter {
+	return &TrimWrappedPrefixFormatter{}
+}
+
+// Reset implements Resettable, discarding the last message that the formatter has seen.
+func (formatter *TrimWrappedPrefixFormatter) Reset() {
+	formatter.lastRawMessage = ""
+}
+
+// Clone implements Cloneable, returning a TrimWrappedPrefixFormatter that has not seen a previous message.
+func (formatter *TrimWrappedPrefixFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.lastRawMessage = ""
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for TrimWrappedPrefixFormatter.
+func (formatter *TrimWrappedPrefixFormatter) FormatTrace(previousMessages []string, message string) string {
+	// Only the first message of an error is its actual message; the rest are details, which we leave as is.
+	if len(previousMessages) != 0 {
+		return message
+	}
+
+	lastMessage := formatter.lastRawMessage
+	formatter.lastRawMessage = message
+	wrappedSuffix := ": " + strings.TrimSpace(lastMessage)
+	trimmedMessage := strings.TrimRightFunc(message, unicode.IsSpace)
+	if lastMessage == "" || !strings.HasSuffix(trimmedMessage, wrappedSuffix) {
+		return message
+	}
+
+	return strings.TrimSuffix(trimmedMessage, wrappedSuffix)
+}
+
+// DOTFormatter renders the trace as a GraphViz DOT digraph, where each error is a node, and each error has an edge to
+// the error it wraps. Only the message of each error is used as its node's label; any detailed output is omitted.
+// Because the opening and closing lines of the digraph are emitted with the first and last errors of the trace, the
+// formatter must be driven through a full trace (i.e. with Tracer.Trace or Tracer.Format) to produce a complete
+// digraph. Reading only some of the errors with ReadNext or Read will leave the digraph without its closing "}".
+type DOTFormatter struct{}
+
+// dotLabelReplacer escapes a string so it may be used within a quoted DOT label.
+var dotLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// NewDOTFormatter makes a new DOTFormatter.
+func NewDOTFormatter() *DOTFormatter {
+	return &DOTFormatter{}
+}
+
+// FormatFields implements FieldsFormatter, writing the fields as a DOT comment, so that the digraph remains valid.
+func (formatter DOTFormatter) FormatFields(fields map[string]interface{}) string {
+	return "// " + formatFieldsLogfmt(fields)
+}
+
+// FormatTrace formats the message as if it were the only error in the trace, producing a full digraph with one node.
+func (formatter DOTFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{Last: true}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for DOTFormatter.
+func (formatter DOTFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	// Only the first message of an error is its actual message; the rest are details, which we omit.
+	if len(previousMessages) != 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	if context.Index == 0 {
+		builder.WriteString("digraph trace {\n")
+	}
+
+	label := dotLabelReplacer.Replace(strings.TrimSpace(message))
+	fmt.Fprintf(&builder, "\t%s [label=\"%s\"];", dotNodeName(context.Group, context.Depth), label)
+	if context.Depth > 0 {
+		wrappedNode := dotNodeName(context.Group, context.Depth-1)
+		fmt.Fprintf(&builder, "\n\t%s -> %s;", dotNodeName(context.Group, context.Depth), wrappedNode)
+	}
+
+	if context.Last {
+		builder.WriteString("\n}")
+	}
+
+	return builder.String()
+}
+
+// dotNodeName produces the name of the DOT node for the error at the given position.
+func dotNodeName(group int, depth int) string {
+	return fmt.Sprintf("err%d_%d", group, depth)
+}
+
+// TimingFormatter annotates each error that carries a timestamp with the time elapsed since the timestamp of the last
+// error before it in the trace that carried one (e.g. "[+12ms] aw shucks"). An error carries a timestamp if it
+// implements the following interface.
+//
+//	interface {
+//		Timestamp() time.Time
+//	}
+//
+// The first error with a timestamp is annotated with "[+0s]", and errors without timestamps are left without an
+// annotation. With NewestFirstOrdering, the elapsed times will generally be negative. Only the first message of each
+// error is annotated; any detailed output is left as is. The elapsed time is measured from the last timestamp this
+// formatter saw, whichever trace it came from, so it must only be used by one trace at a time.
+type TimingFormatter struct {
+	// holds the last timestamp seen, if hasLastTimestamp is set
+	lastTimestamp    time.Time
+	hasLastTimestamp bool
+}
+
+// NewTimingFormatter makes a new TimingFormatter.
+func NewTimingFormatter() *TimingFormatter {
+	return &TimingFormatter{}
+}
+
+// Reset implements Resettable, discarding the last timestamp that the formatter has seen.
+func (formatter *TimingFormatter) Reset() {
+	formatter.lastTimestamp = time.Time{}
+	formatter.hasLastTimestamp = false
+}
+
+// Clone implements Cloneable, returning a TimingFormatter that has not seen a timestamp yet.
+func (formatter *TimingFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.lastTimestamp = time.Time{}
+	clone.hasLastTimestamp = false
+
+	return &clone
+}
+
+// FormatTrace returns the message as is, as without the error that the message belongs to, there is no timestamp.
+func (formatter *TimingFormatter) FormatTrace(previousMessages []string, message string) string {
+	return message
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for TimingFormatter.
+func (formatter *TimingFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	timestamper, hasTimestamp := context.Err.(interface{ Timestamp() time.Time })
+	if len(previousMessages) != 0 || !hasTimestamp {
+		return message
+	}
+
+	timestamp := timestamper.Timestamp()
+	elapsed := time.Duration(0)
+	if formatter.hasLastTimestamp {
+		elapsed = timestamp.Sub(formatter.lastTimestamp)
+	}
+
+	formatter.lastTimestamp = timestamp
+	formatter.hasLastTimestamp = true
+	if elapsed < 0 {
+		return fmt.Sprintf("[%s] %s", elapsed, message)
+	}
+
+	return fmt.Sprintf("[+%s] %s", elapsed, message)
+}
+
+// WhenFormatter annotates each error that records when it occurred with that time, and the time elapsed since the root
+// error occurred (e.g. "[2019-10-20T12:00:00.012Z +12ms] aw shucks"), which shows how long the error took to propagate
+// to each layer. An error records when it occurred if it implements the following interface.
+//
+//	interface {
+//		When() time.Time
+//	}
+//
+// The root error is the innermost error of the chain, found with xerrors.Unwrap, that records when it occurred, and so
+// the elapsed times do not depend on the ordering of the trace. Errors that do not record when they occurred are left
+// without an annotation. Each message is first passed to an inner formatter, and the annotation is inserted after any
+// whitespace that the resulting message starts with. The inner formatter defaults to NilFormatter. Only the first
+// message of each error is annotated; any detailed output is left as is.
+type WhenFormatter struct {
+	formatter TraceFormatter
+	layout    string
+}
+
+// NewWhenFormatter makes a new WhenFormatter.
+func NewWhenFormatter(options ...func(*WhenFormatter) error) (*WhenFormatter, error) {
+	formatter := &WhenFormatter{
+		formatter: NilFormatter{},
+		layout:    time.RFC3339Nano,
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct WhenFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. The root error is found anew from the chain of each error, so nothing is held between
+// errors, and this only resets the inner formatter, if it is Resettable.
+func (formatter *WhenFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a WhenFormatter with the same time layout, whose inner formatter is a clone of
+// this one's, if it is Cloneable.
+func (formatter *WhenFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace passes the message to the inner formatter without annotating it, as without the error that the message
+// belongs to, there is no time to show.
+func (formatter *WhenFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for WhenFormatter, passing the context to the
+// inner formatter if it is a ContextualTraceFormatter.
+func (formatter *WhenFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+	if len(previousMessages) != 0 || formattedMessage == Dropped {
+		return formattedMessage
+	}
+
+	when, hasWhen := errorWhen(context.Err)
+	if !hasWhen {
+		return formattedMessage
+	}
+
+	rootWhen := when
+	for err := xerrors.Unwrap(context.Err); err != nil; err = xerrors.Unwrap(err) {
+		if wrappedWhen, wrappedHasWhen := errorWhen(err); wrappedHasWhen {
+			rootWhen = wrappedWhen
+		}
+	}
+
+	elapsed := when.Sub(rootWhen)
+	annotation := fmt.Sprintf("[%s +%s] ", when.Format(formatter.layout), elapsed)
+	if elapsed < 0 {
+		annotation = fmt.Sprintf("[%s %s] ", when.Format(formatter.layout), elapsed)
+	}
+
+	return insertAfterLeadingSpace(formattedMessage, annotation)
+}
+
+// errorWhen gets the time that the given error occurred, if it records one with a When method.
+func errorWhen(err error) (time.Time, bool) {
+	whener, hasWhen := err.(interface{ When() time.Time })
+	if !hasWhen {
+		return time.Time{}, false
+	}
+
+	return whener.When(), true
+}
+
+// BulletFormatter prefixes each message, other than the first message of an error, with a bullet. The bullet is chosen
+// by cycling through the configured bullets based on the number of messages that came before it, so that with the
+// default bullets of "• " and "◦ ", the second message is prefixed with "• ", the third with "◦ ", the fourth with
+// "• ", and so on. Each message is first passed to an inner formatter, and the bullet is inserted after any whitespace
+// that the resulting message starts with, so wrapping a NestedMessageFormatter will place the bullets after its
+// indentation. The inner formatter defaults to NilFormatter.
+type BulletFormatter struct {
+	bullets   []string
+	formatter TraceFormatter
+}
+
+// NewBulletFormatter makes a new BulletFormatter.
+func NewBulletFormatter(options ...func(*BulletFormatter) error) (*BulletFormatter, error) {
+	formatter := &BulletFormatter{
+		bullets:   []string{"• ", "◦ "},
+		formatter: NilFormatter{},
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct BulletFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. Each bullet is chosen by the number of messages before it within the same error, so
+// this only resets the inner formatter, if it is Resettable.
+func (formatter *BulletFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a BulletFormatter with the same bullets, whose inner formatter is a clone of
+// this one's, if it is Cloneable.
+func (formatter *BulletFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for BulletFormatter.
+func (formatter *BulletFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.bulletMessage(previousMessages, formatter.formatter.FormatTrace(previousMessages, message))
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for BulletFormatter, passing the context to
+// the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *BulletFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+
+	return formatter.bulletMessage(previousMessages, formattedMessage)
+}
+
+// bulletMessage inserts the bullet for the given position into the already formatted message.
+func (formatter *BulletFormatter) bulletMessage(previousMessages []string, message string) string {
+	if len(previousMessages) == 0 {
+		return message
+	}
+
+	bullet := formatter.bullets[(len(previousMessages)-1)%len(formatter.bullets)]
+
+	return insertAfterLeadingSpace(message, bullet)
+}
+
+// insertAfterLeadingSpace inserts the given string into the message, after any whitespace that the message starts with.
+func insertAfterLeadingSpace(message string, inserted string) string {
+	contentStart := len(message) - len(strings.TrimLeftFunc(message, unicode.IsSpace))
+
+	return message[:contentStart] + inserted + message[contentStart:]
+}
+
+// RootHighlightFormatter prefixes the first message of the root cause with a marker, so that it may be easily found
+// within the trace. Each message is first passed to an inner formatter, and the marker is inserted after any whitespace
+// that the resulting message starts with. The root cause can only be identified through the TraceContext of the error,
+// so when this formatter is used outside of a Tracer through FormatTrace, no marker is added. The marker defaults to
+// "[root] ", and the inner formatter defaults to NilFormatter.
+type RootHighlightFormatter struct {
+	marker    string
+	formatter TraceFormatter
+}
+
+// NewRootHighlightFormatter makes a new RootHighlightFormatter.
+func NewRootHighlightFormatter(
+	options ...func(*RootHighlightFormatter) error,
+) (*RootHighlightFormatter, error) {
+	formatter := &RootHighlightFormatter{
+		marker:    "[root] ",
+		formatter: NilFormatter{},
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct RootHighlightFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable, passing the reset along to the inner formatter, if it is Resettable, as whether an error
+// is the root cause is known from its context alone.
+func (formatter *RootHighlightFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a RootHighlightFormatter with the same highlight, whose inner formatter is a
+// clone of this one's, if it is Cloneable.
+func (formatter *RootHighlightFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message with the inner formatter. As the root cause can not be identified without the
+// context of the error, no marker is added.
+func (formatter *RootHighlightFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.formatter.FormatTrace(previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for RootHighlightFormatter, passing the
+// context to the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *RootHighlightFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+	if !context.IsRoot() || len(previousMessages) != 0 {
+		return formattedMessage
+	}
+
+	return insertAfterLeadingSpace(formattedMessage, formatter.marker)
+}
+
+// HyperlinkFormatter turns each file and line number reference (e.g. "/home/nick/main.go:12") within the messages of
+// the trace into an OSC 8 terminal hyperlink, allowing the reference to be opened directly from a supporting terminal.
+// Terminals that do not support OSC 8 will display the reference as plain text. Only references whose file contains a
+// path separator are linked, and as such references generally only appear in detailed output, this formatter has little
+// effect without it. Each message is first passed to an inner formatter, which defaults to NilFormatter.
+//
+// The target of each hyperlink is produced from a URL template, in which "{file}" and "{line}" are replaced with the
+// file and line number of the reference. The template defaults to "file://{file}", but may be set to open an editor
+// instead (e.g. "vscode://file{file}:{line}").
+type HyperlinkFormatter struct {
+	urlTemplate string
+	formatter   TraceFormatter
+}
+
+// fileLinePattern matches a file and line number reference, such as those that xerrors includes in detailed output.
+var fileLinePattern = regexp.MustCompile(`((?:[A-Za-z]:)?[^\s:]*[/\\][^\s:]*):(\d+)`)
+
+// NewHyperlinkFormatter makes a new HyperlinkFormatter.
+func NewHyperlinkFormatter(options ...func(*HyperlinkFormatter) error) (*HyperlinkFormatter, error) {
+	formatter := &HyperlinkFormatter{
+		urlTemplate: "file://{file}",
+		formatter:   NilFormatter{},
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct HyperlinkFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. Each location is linked on its own, so the only state to reset is that of the inner
+// formatter, if it is Resettable.
+func (formatter *HyperlinkFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a HyperlinkFormatter with the same URL template, whose inner formatter is a
+// clone of this one's, if it is Cloneable.
+func (formatter *HyperlinkFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for HyperlinkFormatter.
+func (formatter *HyperlinkFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.linkReferences(formatter.formatter.FormatTrace(previousMessages, message))
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for HyperlinkFormatter, passing the context
+// to the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *HyperlinkFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	return formatter.linkReferences(formatWithContext(formatter.formatter, context, previousMessages, message))
+}
+
+// linkReferences wraps each file and line number reference in the message with an OSC 8 hyperlink.
+func (formatter *HyperlinkFormatter) linkReferences(message string) string {
+	return fileLinePattern.ReplaceAllStringFunc(message, func(reference string) string {
+		submatches := fileLinePattern.FindStringSubmatch(reference)
+		url := strings.NewReplacer("{file}", submatches[1], "{line}", submatches[2]).Replace(formatter.urlTemplate)
+
+		return "\x1b]8;;" + url + "\x1b\\" + reference + "\x1b]8;;\x1b\\"
+	})
+}
+
+// DetailColorFormatter styles the messages of the trace with ANSI escape codes, so that the message of each error can
+// be told apart from its detailed output (e.g. the function, file, and line number of each frame) at a glance. The
+// first piece of output of each error is treated as its message, and any pieces that follow it are treated as its
+// detail. By default, messages are bold and detail is faint. Styles are applied to each line separately, excluding any
+// whitespace that surrounds it, and each styled line ends with a reset code, so that styles never leak across lines or
+// into the indentation of other formatters. Each message is first passed to an inner formatter, which defaults to
+// NilFormatter. If color is disabled with DetailColorEnabled, the output of the inner formatter is left unchanged.
+type DetailColorFormatter struct {
+	messageStyle string
+	frameStyle   string
+	enabled      bool
+	formatter    TraceFormatter
+}
+
+// ansiReset is the ANSI escape code that resets all styles.
+const ansiReset = "\x1b[0m"
+
+// NewDetailColorFormatter makes a new DetailColorFormatter.
+func NewDetailColorFormatter(options ...func(*DetailColorFormatter) error) (*DetailColorFormatter, error) {
+	formatter := &DetailColorFormatter{
+		messageStyle: "\x1b[1m",
+		frameStyle:   "\x1b[2m",
+		enabled:      true,
+		formatter:    NilFormatter{},
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct DetailColorFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable, passing the reset on to the inner formatter if it is Resettable. Colors are applied line
+// by line, so there is nothing else to discard.
+func (formatter *DetailColorFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a DetailColorFormatter with the same styles, whose inner formatter is a clone
+// of this one's, if it is Cloneable.
+func (formatter *DetailColorFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for DetailColorFormatter.
+func (formatter *DetailColorFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.styleMessage(previousMessages, formatter.formatter.FormatTrace(previousMessages, message))
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for DetailColorFormatter, passing the
+// context to the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *DetailColorFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+
+	return formatter.styleMessage(previousMessages, formattedMessage)
+}
+
+// styleMessage applies the style for the given position to each line of the already formatted message.
+func (formatter *DetailColorFormatter) styleMessage(previousMessages []string, message string) string {
+	style := formatter.frameStyle
+	if len(previousMessages) == 0 {
+		style = formatter.messageStyle
+	}
+
+	if !formatter.enabled || style == "" {
+		return message
+	}
+
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		content := strings.TrimSpace(line)
+		if content == "" {
+			continue
+		}
+
+		contentStart := strings.Index(line, content)
+		contentEnd := contentStart + len(content)
+		lines[i] = line[:contentStart] + style + content + ansiReset + line[contentEnd:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ControlEscapeFormatter replaces the non-printable characters within each message, such as escape codes or null bytes
+// that were read from binary data, with their Go escape sequences (e.g. "\x1b" or "\x00"), so that a malformed message
+// can not scramble a terminal. Printable characters, including those outside of ASCII, are left as is, and invalid
+// UTF-8 is escaped byte by byte. Tabs are never escaped, and newlines are only escaped if EscapeNewlines is set. Each
+// message is escaped before it is passed to an inner formatter, which defaults to NilFormatter, so that the newlines
+// and escape codes added by the inner formatter are left intact.
+type ControlEscapeFormatter struct {
+	escapeNewlines bool
+	formatter      TraceFormatter
+}
+
+// NewControlEscapeFormatter makes a new ControlEscapeFormatter.
+func NewControlEscapeFormatter(
+	options ...func(*ControlEscapeFormatter) error,
+) (*ControlEscapeFormatter, error) {
+	formatter := &ControlEscapeFormatter{formatter: NilFormatter{}}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct ControlEscapeFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. Escaping is done one message at a time with nothing remembered, so only a Resettable
+// inner formatter is affected.
+func (formatter *ControlEscapeFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a ControlEscapeFormatter that escapes newlines the same way, whose inner
+// formatter is a clone of this one's, if it is Cloneable.
+func (formatter *ControlEscapeFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for ControlEscapeFormatter.
+func (formatter *ControlEscapeFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.formatter.FormatTrace(previousMessages, formatter.escapeControl(message))
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for ControlEscapeFormatter, passing the
+// context to the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *ControlEscapeFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	return formatWithContext(formatter.formatter, context, previousMessages, formatter.escapeControl(message))
+}
+
+// escapeControl replaces each non-printable character in the message with its escape sequence.
+func (formatter *ControlEscapeFormatter) escapeControl(message string) string {
+	builder := strings.Builder{}
+	for len(message) > 0 {
+		char, size := utf8.DecodeRuneInString(message)
+		switch {
+		case char == utf8.RuneError && size == 1:
+			fmt.Fprintf(&builder, "\\x%02x", message[0])
+		case char == '\t' || (char == '\n' && !formatter.escapeNewlines) || unicode.IsPrint(char):
+			builder.WriteString(message[:size])
+		default:
+			quoted := strconv.QuoteRuneToASCII(char)
+			builder.WriteString(quoted[1 : len(quoted)-1])
+		}
+
+		message = message[size:]
+	}
+
+	return builder.String()
+}
+
+// CollapseCounterFormatter collapses runs of consecutive errors in a chain whose messages differ only by numbers, such
+// as those produced by retry libraries (e.g. "attempt 1 failed" wrapped by "attempt 2 failed"), into a single message
+// that counts them, such as "attempt N failed (x2)".
+//
+// The heuristic is as follows: the messages of two errors are considered the same if they are identical once every run
+// of digits in them is masked. Only the first message of each error is compared, without any formatting or detailed
+// output. Each run is outputted in place of its outermost error, i.e. the one that wraps the others, and the rest of
+// the errors in the run are dropped, as with Dropped; in the outputted message, each run of digits that is not the same
+// for every error in the run is replaced with "N". The errors that an error wraps are found through xerrors.Unwrap.
+//
+// This formatter relies on the context of each error (see TraceContext), so when it is used outside of a Tracer
+// through FormatTrace, messages are left as is. Unlike most formatters that compare messages, it holds no state between
+// messages, so it works with either ordering.
+type CollapseCounterFormatter struct{}
+
+// digitRunPattern matches a run of digits.
+var digitRunPattern = regexp.MustCompile(`\d+`)
+
+// NewCollapseCounterFormatter makes a new CollapseCounterFormatter.
+func NewCollapseCounterFormatter() *CollapseCounterFormatter {
+	return &CollapseCounterFormatter{}
+}
+
+// FormatTrace returns the message as is. As runs of errors can not be identified without the context of the error,
+// nothing is collapsed.
+func (formatter *CollapseCounterFormatter) FormatTrace(previousMessages []string, message string) string {
+	return message
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for CollapseCounterFormatter.
+func (formatter *CollapseCounterFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	if len(previousMessages) != 0 || context.Err == nil {
+		return message
+	}
+
+	maskedMessage := maskDigits(plainMessage(context.Err))
+	if context.Wrapper != nil && maskDigits(plainMessage(context.Wrapper)) == maskedMessage {
+		return Dropped
+	}
+
+	runMessages := []string{plainMessage(context.Err)}
+	for wrapped := xerrors.Unwrap(context.Err); wrapped != nil; wrapped = xerrors.Unwrap(wrapped) {
+		wrappedMessage := plainMessage(wrapped)
+		if maskDigits(wrappedMessage) != maskedMessage {
+			break
+		}
+
+		runMessages = append(runMessages, wrappedMessage)
+	}
+
+	if len(runMessages) == 1 {
+		return message
+	}
+
+	return fmt.Sprintf("%s (x%d)", replaceVaryingDigits(message, runMessages), len(runMessages))
+}
+
+// maskDigits replaces each run of digits in the message with a placeholder.
+func maskDigits(message string) string {
+	return digitRunPattern.ReplaceAllString(message, "\x00")
+}
+
+// replaceVaryingDigits replaces each run of digits in the message with "N" if the corresponding run of digits is not
+// the same in all of the given messages, which must all be the same once masked.
+func replaceVaryingDigits(message string, runMessages []string) string {
+	digitRuns := make([][]string, len(runMessages))
+	for i, runMessage := range runMessages {
+		digitRuns[i] = digitRunPattern.FindAllString(runMessage, -1)
+	}
+
+	// If the message has been changed from those of the errors, the runs of digits can not be matched up.
+	if maskDigits(message) != maskDigits(runMessages[0]) {
+		return message
+	}
+
+	runIndex := -1
+
+	return digitRunPattern.ReplaceAllStringFunc(message, func(digits string) string {
+		runIndex++
+		for _, runs := range digitRuns {
+			if runs[runIndex] != digits {
+				return "N"
+			}
+		}
+
+		return digits
+	})
+}
+
+// SummaryFormatter starts the trace of each chain with a compact, one-line summary of it, made up of the message of its
+// root cause and the message of the error that wraps all others, followed by the full trace of the chain, as follows.
+//
+//	things broke :(: oh no
+//	things broke :(
+//	aw shucks
+//	oh no
+//
+// The summary is placed before the first error of each chain that is outputted, regardless of the Tracer's ordering.
+// Its two messages are separated by ": ", and if the chain holds a single error, the summary is just its message. Only
+// the message of each error is used, without any formatting or detailed output, and each line of a message that spans
+// multiple lines is joined to the next with a space. Each message is passed to an inner formatter before the summary
+// is inserted. The inner formatter defaults to NilFormatter.
+//
+// The chain can only be found through the TraceContext of the error, so when this formatter is used outside of a
+// Tracer through FormatTrace, no summary is added. Which chains have been summarized is remembered until the formatter
+// is reset, so a SummaryFormatter shared by two traces running at once may leave a chain without its summary.
+type SummaryFormatter struct {
+	formatter TraceFormatter
+	// whether or not any chain has been summarized since the formatter was last reset
+	started bool
+	// the Group of the last chain that was summarized
+	lastGroup int
+}
+
+// NewSummaryFormatter makes a new SummaryFormatter.
+func NewSummaryFormatter(options ...func(*SummaryFormatter) error) (*SummaryFormatter, error) {
+	formatter := &SummaryFormatter{formatter: NilFormatter{}}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct SummaryFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable, forgetting which chains have been summarized, and resetting the inner formatter if it
+// is Resettable.
+func (formatter *SummaryFormatter) Reset() {
+	formatter.started = false
+	formatter.lastGroup = 0
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a copy of the formatter with the same options, but none of the state it holds
+// for the current trace, whose inner formatter is a clone of this formatter's inner formatter if it is Cloneable.
+func (formatter *SummaryFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+	clone.started = false
+	clone.lastGroup = 0
+
+	return &clone
+}
+
+// FormatTrace formats the message with the inner formatter. As the chain can not be found without the context of the
+// error, no summary is added.
+func (formatter *SummaryFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.formatter.FormatTrace(previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for SummaryFormatter, passing the context to
+// the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *SummaryFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+	if len(previousMessages) != 0 || len(context.Chain) == 0 ||
+		(formatter.started && formatter.lastGroup == context.Group) {
+		return formattedMessage
+	}
+
+	formatter.started = true
+	formatter.lastGroup = context.Group
+
+	return summarizeChain(context.Chain) + "\n" + formattedMessage
+}
+
+// summarizeChain produces the summary of the given chain, which holds the originating error at len(chain) - 1.
+func summarizeChain(chain []error) string {
+	rootMessage := strings.Join(strings.Fields(plainMessage(chain[len(chain)-1])), " ")
+	if len(chain) == 1 {
+		return rootMessage
+	}
+
+	return rootMessage + ": " + strings.Join(strings.Fields(plainMessage(chain[0])), " ")
+}
+
+// MarkdownOrderedFormatter renders the trace as a Markdown ordered list, with one item per error, numbered by the
+// position of the error within the output of its chain. Any detailed output of an error is nested beneath its item as
+// a bulleted sub-list, with one sub-item per line, as follows.
+//
+//  1. things broke :\(
+//  2. aw shucks
+//     - main\.main
+//     - /home/nick/main\.go:12
+//
+// Markdown-significant characters within messages are escaped with backslashes, so that they render literally. For a
+// Tracer constructed with NewMultiTracer, the numbering restarts for each top-level error; a GroupSeparator of "\n"
+// will keep the lists of each top-level error apart. The item number is carried from one call to the next, so the
+// numbering of two traces that share the formatter at the same time would be interleaved.
+type MarkdownOrderedFormatter struct {
+	// the number of the last item that was outputted
+	itemNumber int
+	// the Group of the last item that was outputted
+	lastGroup int
+}
+
+// markdownReplacer escapes the characters that are significant in Markdown.
+var markdownReplacer = func() *strings.Replacer {
+	replacements := []string{}
+	for _, char := range "\\`*_{}[]()<>#+-.!|~" {
+		replacements = append(replacements, string(char), "\\"+string(char))
+	}
+
+	return strings.NewReplacer(replacements...)
+}()
+
+// NewMarkdownOrderedFormatter makes a new MarkdownOrderedFormatter.
+func NewMarkdownOrderedFormatter() *MarkdownOrderedFormatter {
+	return &MarkdownOrderedFormatter{}
+}
+
+// Reset implements Resettable, restarting the numbering of the list.
+func (formatter *MarkdownOrderedFormatter) Reset() {
+	formatter.itemNumber = 0
+	formatter.lastGroup = 0
+}
+
+// Clone implements Cloneable, returning a MarkdownOrderedFormatter whose numbering starts from the first item.
+func (formatter *MarkdownOrderedFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.itemNumber = 0
+	clone.lastGroup = 0
+
+	return &clone
+}
+
+// FormatTrace formats the message as if it belonged to the first top-level error, as without the context of the error
+// that the message belongs to, its group is not known.
+func (formatter *MarkdownOrderedFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for MarkdownOrderedFormatter.
+func (formatter *MarkdownOrderedFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	if len(previousMessages) == 0 {
+		if context.Group != formatter.lastGroup {
+			formatter.itemNumber = 0
+			formatter.lastGroup = context.Group
+		}
+
+		formatter.itemNumber++
+		// A list item can not span multiple lines without being indented, so the message is kept to a single line.
+		singleLineMessage := strings.Join(strings.Fields(message), " ")
+
+		return fmt.Sprintf("%d. %s", formatter.itemNumber, markdownReplacer.Replace(singleLineMessage))
+	}
+
+	indentation := strings.Repeat(" ", len(strconv.Itoa(formatter.itemNumber))+len(". "))
+	builder := strings.Builder{}
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			builder.WriteString("\n" + indentation + "- " + markdownReplacer.Replace(line))
+		}
+	}
+
+	return builder.String()
+}
+
+// CodeFormatter prefixes the first message of each error with a short code derived from the message, such as
+// "[E-D541FFAB] things broke", so that an error may be looked up in a runbook by its code, even when its message holds
+// details that vary between occurrences. Each message is first passed to an inner formatter, and the code is inserted
+// after any whitespace that the resulting message starts with. The inner formatter defaults to NilFormatter. Messages
+// other than the first message of an error, such as detailed output, are left as is.
+//
+// The code is derived from the message as it was given to the formatter, before the inner formatter is applied, as
+// follows, and so it is stable between traces, processes, and versions of this package:
+//
+//  1. Leading and trailing whitespace is removed.
+//  2. Each run of non-whitespace characters that contains a "/" or "\" is considered a path, and replaced with
+//     "<path>".
+//  3. Each remaining run of ASCII digits is replaced with "N".
+//  4. Each run of whitespace is replaced with a single space.
+//  5. The code is "E-" followed by the CRC-32 checksum (IEEE polynomial) of the resulting message, as eight uppercase
+//     hexadecimal digits.
+//
+// For example, "could not open /tmp/foo.txt after 3 tries" and "could not open /var/bar after 12 tries" are both
+// normalized to "could not open <path> after N tries", and so share the code "E-E3882904". As the code is computed
+// from the message alone, identical messages always receive identical codes, and no state is held between messages.
+type CodeFormatter struct {
+	formatter TraceFormatter
+}
+
+// codePathPattern matches a run of non-whitespace characters that holds a path separator.
+var codePathPattern = regexp.MustCompile(`[^\s]*[/\\][^\s]*`)
+
+// NewCodeFormatter makes a new CodeFormatter.
+func NewCodeFormatter(options ...func(*CodeFormatter) error) (*CodeFormatter, error) {
+	formatter := &CodeFormatter{
+		formatter: NilFormatter{},
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct CodeFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. As a code depends on nothing but its message, this only resets the inner formatter, if
+// it is Resettable.
+func (formatter *CodeFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a CodeFormatter whose inner formatter is a clone of this one's, if it is
+// Cloneable.
+func (formatter *CodeFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as dictated by the contract for CodeFormatter.
+func (formatter *CodeFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for CodeFormatter, passing the context to the
+// inner formatter if it is a ContextualTraceFormatter.
+func (formatter *CodeFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+	if len(previousMessages) != 0 || formattedMessage == Dropped {
+		return formattedMessage
+	}
+
+	return insertAfterLeadingSpace(formattedMessage, "["+errorCode(message)+"] ")
+}
+
+// errorCode derives the code of the given message, as described by CodeFormatter.
+func errorCode(message string) string {
+	normalized := strings.TrimSpace(message)
+	normalized = codePathPattern.ReplaceAllString(normalized, "<path>")
+	normalized = digitRunPattern.ReplaceAllString(normalized, "N")
+	normalized = strings.Join(strings.Fields(normalized), " ")
+
+	return fmt.Sprintf("E-%08X", crc32.ChecksumIEEE([]byte(normalized)))
+}
+
+// ColumnarFormatter lays out each error of a detailed trace as a single line, made up of the message of the error,
+// the function that it was created in, and its file and line number, with each column aligned using a
+// text/tabwriter.Writer, as follows.
+//
+//	things broke :(
+//	aw shucks        main.run   /home/nick/run.go:140
+//	oh no            main.main  /home/nick/main.go:12
+//
+// The columns are aligned across all of the errors in a chain that have a location, so the rows of the whole chain
+// are computed the first time that one of them is formatted, and held until the next chain. A location is only found
+// within detailed output in the form produced by xerrors.Frame, or through FrameOf. The message of an error with a
+// location is replaced by its row, and the rest of its detailed output is omitted. Errors without a location, such as
+// those produced by errors.New, as well as all errors when detailed output is disabled, are output as a plain line
+// that holds only their message, with runs of whitespace collapsed.
+//
+// This formatter relies on the context of each error (see TraceContext), so when it is used outside of a Tracer
+// through FormatTrace, messages are left as is. For Tracers constructed with NewMultiTracer, each chain is aligned
+// independently. As the rows of the current chain are held between calls, a ColumnarFormatter must only be used by one
+// trace at a time.
+type ColumnarFormatter struct {
+	// holds the row of each error in the current chain, by depth, or an empty string if the error has no location
+	rows []string
+	// holds the group of the chain that rows were computed for
+	rowsGroup int
+}
+
+// NewColumnarFormatter makes a new ColumnarFormatter.
+func NewColumnarFormatter() *ColumnarFormatter {
+	return &ColumnarFormatter{}
+}
+
+// Reset implements Resettable, discarding the rows of the current chain.
+func (formatter *ColumnarFormatter) Reset() {
+	formatter.rows = nil
+	formatter.rowsGroup = 0
+}
+
+// Clone implements Cloneable, returning a ColumnarFormatter that has not computed the rows of any chain.
+func (formatter *ColumnarFormatter) Clone() TraceFormatter {
+	return NewColumnarFormatter()
+}
+
+// FormatTrace returns the message as is. As the rest of the chain is not known without the context of the error,
+// nothing is aligned.
+func (formatter *ColumnarFormatter) FormatTrace(previousMessages []string, message string) string {
+	return message
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for ColumnarFormatter.
+func (formatter *ColumnarFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	if context.Err == nil {
+		return message
+	} else if len(previousMessages) == 0 {
+		return strings.Join(strings.Fields(message), " ")
+	} else if len(previousMessages) > 1 {
+		return ""
+	}
+
+	if formatter.rows == nil || formatter.rowsGroup != context.Group || len(formatter.rows) != context.ChainLength {
+		formatter.rows = alignedRows(context.Chain)
+		formatter.rowsGroup = context.Group
+	}
+
+	row := formatter.rows[context.Depth]
+	if row == "" {
+		return ""
+	}
+
+	// The row holds the message of the error in its first column, so the plain line is replaced with it.
+	previousMessages[0] = ""
+
+	return row
+}
+
+// alignedRows produces the row of each error in the given chain, as described by ColumnarFormatter, with the columns
+// of all rows aligned. The rows are indexed by the depth of their error, and an error without a location has an empty
+// row.
+func alignedRows(chain []error) []string {
+	rowDepths := []int{}
+	buffer := bytes.NewBuffer([]byte{})
+	tabWriter := tabwriter.NewWriter(buffer, 0, 0, 2, ' ', 0)
+	for i, err := range chain {
+		function, location, hasLocation := errorLocation(err)
+		if !hasLocation {
+			continue
+		}
+
+		message := strings.Join(strings.Fields(plainMessage(err)), " ")
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", message, function, location)
+		// The chain runs from the error that wraps all others to the root cause, which has a depth of zero.
+		rowDepths = append(rowDepths, len(chain)-1-i)
+	}
+
+	// Flushing can not fail when writing to a bytes.Buffer.
+	tabWriter.Flush()
+
+	rows := make([]string, len(chain))
+	for i, row := range strings.SplitN(buffer.String(), "\n", len(rowDepths)) {
+		rows[rowDepths[i]] = strings.TrimSuffix(row, "\n")
+	}
+
+	return rows
+}
+
+// errorLocation finds the function that the given error was created in, and its file and line number, if the error
+// has a location.
+func errorLocation(err error) (function string, location string, hasLocation bool) {
+	if frame, isFrameErr := FrameOf(err); isFrameErr {
+		return frame.Function, fmt.Sprintf("%s:%d", frame.File, frame.Line), frame.File != ""
+	}
+
+	errFormatter, isFormatter := err.(xerrors.Formatter)
+	if !isFormatter {
+		return "", "", false
+	}
+
+	sprinter := &formatSprinter{detail: true, traceFormatter: NilFormatter{}}
+	errFormatter.FormatError(sprinter)
+	if len(sprinter.messages) < 2 {
+		return "", "", false
+	}
+
+	lines := strings.Split(strings.TrimSpace(strings.Join(sprinter.messages[1:], "")), "\n")
+	if len(lines) != 2 || !fileLinePattern.MatchString(lines[1]) {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]), true
+}
+
+// RFC5424Formatter renders each error as an RFC 5424 structured data element (SD-ELEMENT), so that traces can be
+// included in the STRUCTURED-DATA of a syslog message, as follows.
+//
+//	[xtrace depth="0" msg="things broke"]
+//	[xtrace depth="1" msg="aw shucks" detail="main.main" detail="/home/nick/main.go:12"]
+//
+// The SD-ID of each element defaults to "xtrace", but may be set with StructuredDataID; as RFC 5424 reserves SD-IDs
+// without an "@" for those registered with IANA, an SD-ID that holds a private enterprise number (e.g.
+// "xtrace@32473") should be used when strict conformance is required. Each element holds the depth of the error within
+// its chain (see TraceContext), its message, and one "detail" parameter for each line of its detailed output, which RFC
+// 5424 permits to be repeated. Each line has its surrounding whitespace removed, and blank lines are omitted. As
+// required by RFC 5424, each of '"', '\', and ']' within a value is escaped with a backslash, and any invalid UTF-8 is
+// replaced with U+FFFD.
+//
+// When traced with a Tracer, the elements are separated by newlines, as with any other formatter, whereas RFC 5424
+// requires them to be directly adjacent, so they should be joined (e.g. from ReadNext) before being placed in a syslog
+// message. The lines of an error are gathered across the calls for its messages, so the formatter must not be given
+// the messages of two traces at once.
+type RFC5424Formatter struct {
+	structuredDataID string
+	// holds the lines of the error currently being formatted
+	lines []string
+}
+
+// rfc5424Escaper escapes the characters that RFC 5424 requires to be escaped within a PARAM-VALUE.
+var rfc5424Escaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// NewRFC5424Formatter makes a new RFC5424Formatter.
+func NewRFC5424Formatter(options ...func(*RFC5424Formatter) error) (*RFC5424Formatter, error) {
+	formatter := &RFC5424Formatter{
+		structuredDataID: "xtrace",
+	}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct RFC5424Formatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable, discarding the lines of the error currently being formatted.
+func (formatter *RFC5424Formatter) Reset() {
+	formatter.lines = nil
+}
+
+// Clone implements Cloneable, returning an RFC5424Formatter with the same SD-ID, but none of the lines of the error
+// currently being formatted.
+func (formatter *RFC5424Formatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.lines = nil
+
+	return &clone
+}
+
+// FormatTrace formats the message as if it belonged to the root cause, as without the context of the error that the
+// message belongs to, its depth is not known.
+func (formatter *RFC5424Formatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for RFC5424Formatter.
+func (formatter *RFC5424Formatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	if len(previousMessages) == 0 {
+		formatter.lines = nil
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		if trimmedLine := strings.TrimSpace(line); trimmedLine != "" || len(formatter.lines) == 0 {
+			formatter.lines = append(formatter.lines, trimmedLine)
+		}
+	}
+
+	// The whole element is rebuilt with every message of the error, so that the detailed output may be included in it.
+	for i := range previousMessages {
+		previousMessages[i] = ""
+	}
+
+	element := strings.Builder{}
+	fmt.Fprintf(
+		&element,
+		`[%s depth="%d" msg="%s"`,
+		formatter.structuredDataID,
+		context.Depth,
+		rfc5424Value(formatter.lines[0]),
+	)
+	for _, line := range formatter.lines[1:] {
+		fmt.Fprintf(&element, ` detail="%s"`, rfc5424Value(line))
+	}
+
+	element.WriteString("]")
+
+	return element.String()
+}
+
+// rfc5424Value escapes the given value so that it may be used as a PARAM-VALUE.
+func rfc5424Value(value string) string {
+	return rfc5424Escaper.Replace(strings.ToValidUTF8(value, "\uFFFD"))
+}
+
+// NarrativeFormatter labels the first message of each error with a phrase that describes how it relates to the
+// others, so that a chain reads as a narrative of causes and effects for non-technical audiences, as follows.
+//
+//	Because: things broke
+//	Which caused: aw shucks
+//	Which caused: oh no
+//
+// The label is chosen by the depth of the error (see TraceContext): the root cause of each chain is labeled with the
+// "because" phrase, and every error that wraps another is labeled with the "which caused" phrase. As the depth does not
+// change with the Tracer's ordering, with NewestFirstOrdering the root cause is still labeled with the "because"
+// phrase, but is outputted last. The phrases are the Because and WhichCaused fields of the Tracer's Messages, so they
+// are localized along with the rest of the Tracer's output by WithMessages, unless they are set for this formatter
+// alone with Connectives. Each message is first passed to an inner formatter, and the label, followed by a space, is
+// inserted after any whitespace that the resulting message starts with. The inner formatter defaults to NilFormatter.
+type NarrativeFormatter struct {
+	// The phrases set with Connectives, which are empty if the phrases of the Tracer's Messages are to be used
+	because     string
+	whichCaused string
+	formatter   TraceFormatter
+}
+
+// NewNarrativeFormatter makes a new NarrativeFormatter.
+func NewNarrativeFormatter(options ...func(*NarrativeFormatter) error) (*NarrativeFormatter, error) {
+	formatter := &NarrativeFormatter{formatter: NilFormatter{}}
+	for _, optionFunc := range options {
+		err := optionFunc(formatter)
+		if err != nil {
+			return nil, xerrors.Errorf("Could not construct NarrativeFormatter: %w", err)
+		}
+	}
+
+	return formatter, nil
+}
+
+// Reset implements Resettable. Labels come from the depth of each error rather than from earlier messages, so it is
+// only the inner formatter that is reset, if it is Resettable.
+func (formatter *NarrativeFormatter) Reset() {
+	if resettable, isResettable := formatter.formatter.(Resettable); isResettable {
+		resettable.Reset()
+	}
+}
+
+// Clone implements Cloneable, returning a NarrativeFormatter with the same connectives, whose inner formatter is a
+// clone of this one's, if it is Cloneable.
+func (formatter *NarrativeFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.formatter = cloneFormatter(formatter.formatter)
+
+	return &clone
+}
+
+// FormatTrace formats the message as if it belonged to the root cause, as without the context of the error that the
+// message belongs to, its depth is not known. Unless they were set with Connectives, the default phrases are used.
+func (formatter *NarrativeFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for NarrativeFormatter, passing the context
+// to the inner formatter if it is a ContextualTraceFormatter.
+func (formatter *NarrativeFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formattedMessage := formatWithContext(formatter.formatter, context, previousMessages, message)
+	if len(previousMessages) != 0 || formattedMessage == Dropped {
+		return formattedMessage
+	}
+
+	because, whichCaused := formatter.connectives(context.Messages)
+	label := whichCaused
+	if context.IsRoot() {
+		label = because
+	}
+
+	return insertAfterLeadingSpace(formattedMessage, label+" ")
+}
+
+// connectives gets the phrases that errors are labeled with: those set with Connectives if there are any, and those
+// of the given Messages otherwise, falling back to the defaults for any that are empty.
+func (formatter *NarrativeFormatter) connectives(messages Messages) (because string, whichCaused string) {
+	if formatter.because != "" {
+		return formatter.because, formatter.whichCaused
+	}
+
+	because, whichCaused = messages.Because, messages.WhichCaused
+	if because == "" {
+		because = defaultMessages.Because
+	}
+
+	if whichCaused == "" {
+		whichCaused = defaultMessages.WhichCaused
+	}
+
+	return because, whichCaused
+}
+
+// YAMLFormatter renders the trace as a YAML sequence, with one mapping per error. Each mapping holds the depth of the
+// error within its chain (see TraceContext) and its message, including any detailed output, as follows.
+//
+//	# The trace of "aw shucks", which wraps "things broke"
+//	- depth: 0
+//	  message: things broke
+//	- depth: 1
+//	  message: |-
+//	    aw shucks
+//	    main.main
+//	    /home/nick/main.go:12
+//
+// Each line of a message has its surrounding whitespace removed, and blank lines are omitted. Messages that span a
+// single line are written as plain scalars where YAML allows it, and as double-quoted scalars otherwise (e.g.
+// "true", or "aw shucks: things broke"). Messages that span multiple lines are written as literal block scalars that
+// strip the final line break, so the parsed message never ends with a newline. Each mapping is built up from all of
+// the messages of its error, so a YAMLFormatter must not be given the messages of two traces at once.
+type YAMLFormatter struct {
+	// holds the lines of the error currently being formatted
+	lines []string
+}
+
+// yamlPlainUnsafePattern matches the strings that can not be written as a YAML plain scalar, as they would be parsed
+// as something other than the same string.
+var yamlPlainUnsafePattern = regexp.MustCompile(
+	`^$|^[\s\-?:,\[\]{}#&*!|>'"%@\x60]|\s$|: |:$| #|[\x00-\x1f\x7f]|` +
+		`^(?i:true|false|yes|no|on|off|y|n|null|~)$|^[-+.]?[0-9]`,
+)
+
+// NewYAMLFormatter makes a new YAMLFormatter.
+func NewYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+// Reset implements Resettable, discarding the lines of the error currently being formatted.
+func (formatter *YAMLFormatter) Reset() {
+	formatter.lines = nil
+}
+
+// Clone implements Cloneable, returning a YAMLFormatter without any of the lines of the error currently being
+// formatted.
+func (formatter *YAMLFormatter) Clone() TraceFormatter {
+	clone := *formatter
+	clone.lines = nil
+
+	return &clone
+}
+
+// FormatFields implements FieldsFormatter, writing the fields as a YAML comment, so that the document remains valid.
+func (formatter *YAMLFormatter) FormatFields(fields map[string]interface{}) string {
+	return "# " + formatFieldsLogfmt(fields)
+}
+
+// FormatTrace formats the message as if it belonged to the root cause, as without the context of the error that the
+// message belongs to, its depth is not known.
+func (formatter *YAMLFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext formats the message as dictated by the contract for YAMLFormatter.
+func (formatter *YAMLFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	if len(previousMessages) == 0 {
+		formatter.lines = nil
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		if trimmedLine := strings.TrimSpace(line); trimmedLine != "" {
+			formatter.lines = append(formatter.lines, trimmedLine)
+		}
+	}
+
+	// The whole mapping is rebuilt with every message of the error, so that the detailed output may be included in a
+	// single scalar.
+	for i := range previousMessages {
+		previousMessages[i] = ""
+	}
+
+	return fmt.Sprintf("- depth: %d\n  message: %s", context.Depth, yamlScalar(formatter.lines))
+}
+
+// yamlScalar produces a YAML scalar that holds the given lines, joined by newlines.
+func yamlScalar(lines []string) string {
+	if len(lines) > 1 {
+		return "|-\n    " + strings.Join(lines, "\n    ")
+	}
+
+	value := strings.Join(lines, "")
+	if yamlPlainUnsafePattern.MatchString(value) {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -17,9 +17,16 @@ package xtrace
 */
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
 )
 
 type formatTest struct {
@@ -49,6 +56,21 @@ func handleFormatTestSetupError(t *testing.T, formatter TraceFormatter, err erro
 	return formatter
 }
 
+// traceWithFormatter produces the full trace of the given error, as formatted by the given formatter, using a Tracer
+// constructed with the given options.
+func traceWithFormatter(t *testing.T, formatter TraceFormatter, err error, options ...func(*Tracer) error) string {
+	tracer, constructErr := NewTracer(err, append([]func(*Tracer) error{Formatter(formatter)}, options...)...)
+	if constructErr != nil {
+		t.Log("Could not setup tracer", constructErr)
+		t.FailNow()
+	}
+
+	output := bytes.NewBufferString("")
+	assert.Nil(t, tracer.Trace(output))
+
+	return output.String()
+}
+
 func TestNilFormatter(t *testing.T) {
 	tests := []formatTest{
 		{
@@ -185,11 +207,319 @@ func TestNewLineFormatter(t *testing.T) {
 				assert.Equal(t, []string{"things broke :(\n", "an awful thing happened\n", "aw shucks"}, trace)
 			},
 		},
+		{
+			name: "collapse blank lines",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter(Naive(true), CollapseBlankLines(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{
+					"things broke :(\n\n\n  main.main",
+					"aw shucks\n \n\t\n",
+					"an awful thing happened",
+				}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				expected := []string{"things broke :(\n\n  main.main\n", "aw shucks\n\n", "an awful thing happened"}
+				assert.Equal(t, expected, trace)
+			},
+		},
+		{
+			name: "blank lines not collapsed by default",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter(Naive(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, "things broke :(\n\n\n  main.main")
+				assert.Equal(t, "things broke :(\n\n\n  main.main", output)
+			},
+		},
+		{
+			name: "CRLF line endings",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"things broke :(\r\n", "aw shucks\r", "an awful thing happened\r\n"}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				assert.Equal(t, []string{"things broke :(\n", "aw shucks\n", "an awful thing happened"}, trace)
+			},
+		},
+		{
+			name: "CRLF line endings, naive",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter(Naive(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"things broke :(\r\n  main.main\r\n", "aw shucks\r\n"}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				assert.Equal(t, []string{"things broke :(\n  main.main\n", "aw shucks"}, trace)
+			},
+		},
+		{
+			name: "CRLF output line ending",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter(Naive(true), LineEnding("\r\n"))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"things broke :(\n  main.main", "aw shucks\r\n", "an awful thing happened"}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				expected := []string{"things broke :(\r\n  main.main\r\n", "aw shucks\r\n", "an awful thing happened"}
+				assert.Equal(t, expected, trace)
+			},
+		},
+		{
+			name: "normalize whitespace",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNewLineFormatter(Naive(true), NormalizeWhitespace(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"things\tbroke  :(\n    main.main\t\t(file)\n", "aw \t shucks"}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				assert.Equal(t, []string{"things broke :(\n    main.main (file)\n", "aw shucks"}, trace)
+			},
+		},
 	}
 
 	runFormatTestTable(t, tests)
 }
 
+func TestNewLineFormatter_Clone(t *testing.T) {
+	formatter, err := NewNewLineFormatter(Naive(true), LineEnding("\r\n"))
+	assert.Nil(t, err)
+
+	trace := []string{formatter.FormatTrace(nil, "things broke :(")}
+	clone := formatter.Clone()
+	assert.IsType(t, &NewLineFormatter{}, clone)
+
+	// The clone must not know about the message the original formatter has seen, but must keep its options.
+	cloneTrace := []string{clone.FormatTrace(nil, "aw shucks\n")}
+	cloneTrace = append(cloneTrace, clone.FormatTrace(cloneTrace, "an awful thing happened"))
+	assert.Equal(t, []string{"aw shucks\r\n", "an awful thing happened"}, cloneTrace)
+
+	trace = append(trace, formatter.FormatTrace(trace, "I tried very hard and failed"))
+	assert.Equal(t, []string{"things broke :(\r\n", "I tried very hard and failed"}, trace)
+}
+
+func TestNewLineFormatter_LineEndingInTrace(t *testing.T) {
+	formatter, err := NewNewLineFormatter(LineEnding("\r\n"))
+	assert.Nil(t, err)
+
+	tracer, err := NewTracer(xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")), Formatter(formatter))
+	assert.Nil(t, err)
+
+	// The newlines that the Tracer writes between errors must use the same line ending as the messages themselves.
+	output := fmt.Sprintf("%+v", tracer)
+	assert.Equal(t, strings.Count(output, "\n"), strings.Count(output, "\r\n"), output)
+	lines := strings.Split(output, "\r\n")
+	assert.Equal(t, 4, len(lines), lines)
+	assert.Equal(t, "things broke :(", lines[0])
+	assert.Equal(t, "aw shucks", lines[1])
+	assert.Equal(t, "github.com/ollien/xtrace.TestNewLineFormatter_LineEndingInTrace", lines[2])
+}
+
+func TestNewLineFormatter_LineEndingInTraceHeader(t *testing.T) {
+	formatter, err := NewNewLineFormatter(LineEnding("\r\n"))
+	assert.Nil(t, err)
+
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	output := traceWithFormatter(t, formatter, baseErr, DetailedOutput(false), ShowCount(true), KeepTrailing(true))
+	assert.Equal(t, "2 errors:\r\nthings broke :(\r\naw shucks\r\n", output)
+}
+
+func TestNewLineFormatter_SetNaive(t *testing.T) {
+	formatter, err := NewNewLineFormatter()
+	assert.Nil(t, err)
+	assert.Equal(t, "things broke :(    ", formatter.FormatTrace(nil, "things broke :(\n    "))
+
+	formatter.Reset()
+	formatter.SetNaive(true)
+	assert.Equal(t, "things broke :(\n    ", formatter.FormatTrace(nil, "things broke :(\n    "))
+
+	formatter.Reset()
+	formatter.SetNaive(false)
+	assert.Equal(t, "things broke :(    ", formatter.FormatTrace(nil, "things broke :(\n    "))
+}
+
+func TestNewLineFormatter_TrimStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []func(*NewLineFormatter) error
+		messages []string
+		expected []string
+	}{
+		{
+			name:     "regex",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimRegex)},
+			messages: []string{"things broke :(\n    ", "main.main\n"},
+			expected: []string{"things broke :(\n    ", "main.main"},
+		},
+		{
+			name:     "simple",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimSimple)},
+			messages: []string{"things broke :(\n    ", "main.main\n"},
+			expected: []string{"things broke :(\n    \n", "main.main"},
+		},
+		{
+			name:     "none",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimNone)},
+			messages: []string{"things broke :(", "aw shucks\n", "oh no\n"},
+			expected: []string{"things broke :(\n", "aw shucks\n", "oh no\n"},
+		},
+		{
+			name:     "naive maps onto simple",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimNone), Naive(true)},
+			messages: []string{"things broke :(\n    ", "main.main\n"},
+			expected: []string{"things broke :(\n    \n", "main.main"},
+		},
+		{
+			name:     "non-naive maps onto regex",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimNone), Naive(false)},
+			messages: []string{"things broke :(\n    ", "main.main\n"},
+			expected: []string{"things broke :(\n    ", "main.main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewNewLineFormatter(tt.options...)
+			assert.Nil(t, err)
+
+			trace := []string{}
+			for _, message := range tt.messages {
+				trace = append(trace, formatter.FormatTrace(trace, message))
+			}
+
+			assert.Equal(t, tt.expected, trace)
+		})
+	}
+}
+
+func TestNewLineFormatter_PreserveIndent(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []func(*NewLineFormatter) error
+		messages []string
+		expected []string
+	}{
+		{
+			name:     "indented lines of last message are dropped by default",
+			options:  []func(*NewLineFormatter) error{},
+			messages: []string{"things broke :(", "    main.main\n    /home/nick/main.go:12\n"},
+			expected: []string{"things broke :(\n", "    main.main    "},
+		},
+		{
+			name:     "indented lines of last message are kept",
+			options:  []func(*NewLineFormatter) error{PreserveIndent(true)},
+			messages: []string{"things broke :(", "    main.main\n    /home/nick/main.go:12\n"},
+			expected: []string{"things broke :(\n", "    main.main\n    /home/nick/main.go:12"},
+		},
+		{
+			name:     "trailing indentation is still stripped of newlines",
+			options:  []func(*NewLineFormatter) error{PreserveIndent(true)},
+			messages: []string{"things broke :(\n    main.main\n    "},
+			expected: []string{"things broke :(\n    main.main    "},
+		},
+		{
+			name:     "earlier messages are restored as is",
+			options:  []func(*NewLineFormatter) error{PreserveIndent(true)},
+			messages: []string{"things broke :(\n    main.main\n    ", "/home/nick/main.go:12\n"},
+			expected: []string{"things broke :(\n    main.main\n    ", "/home/nick/main.go:12"},
+		},
+		{
+			name:     "no effect on other strategies",
+			options:  []func(*NewLineFormatter) error{TrimStrategy(TrimSimple), PreserveIndent(true)},
+			messages: []string{"things broke :(", "    main.main\n    /home/nick/main.go:12\n"},
+			expected: []string{"things broke :(\n", "    main.main\n    /home/nick/main.go:12"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewNewLineFormatter(tt.options...)
+			assert.Nil(t, err)
+
+			trace := []string{}
+			for _, message := range tt.messages {
+				trace = append(trace, formatter.FormatTrace(trace, message))
+			}
+
+			assert.Equal(t, tt.expected, trace)
+		})
+	}
+}
+
+func TestTrimStrategy_Invalid(t *testing.T) {
+	_, err := NewNewLineFormatter(TrimStrategy(TrimStrategyMethod(-1)))
+	assert.NotNil(t, err)
+}
+
+func BenchmarkNewLineFormatter_TrimStrategy(b *testing.B) {
+	messages := []string{"things broke :(", "main.main\n    ", "/home/nick/main.go:12\n"}
+	strategies := []struct {
+		name   string
+		method TrimStrategyMethod
+	}{
+		{name: "TrimRegex", method: TrimRegex},
+		{name: "TrimSimple", method: TrimSimple},
+		{name: "TrimNone", method: TrimNone},
+	}
+
+	for _, strategy := range strategies {
+		b.Run(strategy.name, func(b *testing.B) {
+			formatter, _ := NewNewLineFormatter(TrimStrategy(strategy.method))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				trace := make([]string, 0, len(messages))
+				for _, message := range messages {
+					trace = append(trace, formatter.FormatTrace(trace, message))
+				}
+			}
+		})
+	}
+}
+
+func TestLineEnding_Unsupported(t *testing.T) {
+	_, err := NewNewLineFormatter(LineEnding("\r"))
+	assert.NotNil(t, err)
+}
+
 func TestNestedMessageFormatter(t *testing.T) {
 	tests := []formatTest{
 		{
@@ -260,7 +590,1356 @@ func TestNestedMessageFormatter(t *testing.T) {
 				assert.Equal(t, []string{"things broke :(\n", "  an awful thing happened\n", "  aw shucks"}, trace)
 			},
 		},
+		{
+			name: "multi-line messages",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewNestedMessageFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{
+					"things broke :(\n  main.main\n",
+					"main.doThings\n        /home/nick/main.go:12\n",
+				}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				expected := []string{"things broke :(\n\tmain.main\n", "\tmain.doThings\n\t/home/nick/main.go:12"}
+				assert.Equal(t, expected, trace)
+			},
+		},
+		{
+			name: "hanging indentation",
+			setup: func(t *testing.T) TraceFormatter {
+				// Align each line with the text after the first line's prefix, or with the line before it.
+				indentFunc := func(previous string) string {
+					content := strings.TrimLeft(previous, " ")
+					width := utf8.RuneCountInString(previous) - utf8.RuneCountInString(content)
+					if prefixEnd := strings.Index(previous, ": "); width == 0 && prefixEnd != -1 {
+						width = utf8.RuneCountInString(previous[:prefixEnd+2])
+					}
+
+					return strings.Repeat(" ", width)
+				}
+				formatter, err := NewNestedMessageFormatter(IndentToMatch(indentFunc))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{
+					"错误: things broke :(\n  main.main\n",
+					"main.doThings\n        /home/nick/main.go:12\n",
+				}
+				for _, message := range messages {
+					formattedOutput := formatter.FormatTrace(trace, message)
+					trace = append(trace, formattedOutput)
+				}
+
+				expected := []string{
+					"错误: things broke :(\n    main.main\n",
+					"    main.doThings\n    /home/nick/main.go:12",
+				}
+				assert.Equal(t, expected, trace)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestNestedMessageFormatter_Clone(t *testing.T) {
+	formatter, err := NewNestedMessageFormatter(NestingIndentation("  "))
+	assert.Nil(t, err)
+
+	clone := formatter.Clone()
+	assert.IsType(t, &NestedMessageFormatter{}, clone)
+	assert.Equal(t, "  aw shucks", clone.FormatTrace([]string{"things broke :("}, "aw shucks"))
+}
+
+func TestNestedMessageFormatter_DetailedTrace(t *testing.T) {
+	formatter, err := NewNestedMessageFormatter()
+	assert.Nil(t, err)
+
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, err := NewTracer(baseErr, Formatter(formatter), Ordering(NewestFirstOrdering))
+	assert.Nil(t, err)
+
+	message, err := tracer.ReadNext()
+	assert.Nil(t, err)
+	lines := strings.Split(message, "\n")
+	assert.True(t, len(lines) > 1, message)
+	assert.Equal(t, "aw shucks", lines[0])
+	for _, line := range lines[1:] {
+		assert.Regexp(t, "^\t\\S", line)
+	}
+}
+
+func TestNestedMessageFormatter_IndentByDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		ordering TraceOrderingMethod
+		expected string
+	}{
+		{
+			name:     "oldest first",
+			ordering: OldestFirstOrdering,
+			expected: "\t\tthings broke :(\n\taw shucks\noh no",
+		},
+		{
+			name:     "newest first",
+			ordering: NewestFirstOrdering,
+			expected: "oh no\n\taw shucks\n\t\tthings broke :(",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewNestedMessageFormatter(IndentByDepth(true))
+			assert.Nil(t, err)
+
+			baseErr := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")))
+			tracer, err := NewTracer(baseErr, Formatter(formatter), Ordering(tt.ordering), DetailedOutput(false))
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestStatefulFormatters_Clone(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "own state is not carried over",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewMarkdownOrderedFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "1. things broke", formatter.FormatTrace(nil, "things broke"))
+
+				clone := formatter.(Cloneable).Clone()
+				assert.Equal(t, "1. aw shucks", clone.FormatTrace(nil, "aw shucks"))
+				assert.Equal(t, "2. aw shucks", formatter.FormatTrace(nil, "aw shucks"))
+			},
+		},
+		{
+			name: "inner formatter is cloned",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewCodeFormatter(CodeInnerFormatter(NewMarkdownOrderedFormatter()))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				formatter.FormatTrace(nil, "things broke")
+
+				clone := formatter.(Cloneable).Clone()
+				assert.Contains(t, clone.FormatTrace(nil, "aw shucks"), "1. aw shucks")
+				assert.Contains(t, formatter.FormatTrace(nil, "aw shucks"), "2. aw shucks")
+			},
+		},
 	}
 
 	runFormatTestTable(t, tests)
 }
+
+func TestGlobalDedupeFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "no repeats",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				messages := []string{
+					"things broke :(",
+					"an awful thing happened",
+					"aw shucks",
+				}
+				for _, message := range messages {
+					assert.Equal(t, message, formatter.FormatTrace(nil, message))
+				}
+			},
+		},
+		{
+			name: "non-adjacent repeats, annotated",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := []string{}
+				messages := []string{
+					"things broke :(",
+					"retrying",
+					"Things Broke :(",
+					"retrying",
+				}
+				for _, message := range messages {
+					output = append(output, formatter.FormatTrace(nil, message))
+				}
+
+				expected := []string{"things broke :(", "retrying", "Things Broke :( (repeated)", "retrying (repeated)"}
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "non-adjacent repeats, suppressed",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter(SuppressDuplicates(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := []string{}
+				messages := []string{
+					"things broke :(",
+					"retrying",
+					"THINGS BROKE :(",
+					"retrying",
+				}
+				for _, message := range messages {
+					output = append(output, formatter.FormatTrace(nil, message))
+				}
+
+				assert.Equal(t, []string{"things broke :(", "retrying", Dropped, Dropped}, output)
+			},
+		},
+		{
+			name: "detailed output",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := []string{}
+				for i := 0; i < 2; i++ {
+					trace := []string{}
+					for _, message := range []string{"retrying\n    ", "main.main\n    ", "/home/nick/main.go:12\n"} {
+						trace = append(trace, formatter.FormatTrace(trace, message))
+					}
+
+					output = append(output, strings.Join(trace, ""))
+				}
+
+				expected := []string{
+					"retrying\n    main.main\n    /home/nick/main.go:12\n",
+					"retrying (repeated)\n    main.main\n    /home/nick/main.go:12\n",
+				}
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "detailed output, suppressed",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter(SuppressDuplicates(true))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := [][]string{}
+				for i := 0; i < 2; i++ {
+					trace := []string{}
+					for _, message := range []string{"retrying\n    ", "main.main\n    ", "/home/nick/main.go:12\n"} {
+						trace = append(trace, formatter.FormatTrace(trace, message))
+					}
+
+					output = append(output, trace)
+				}
+
+				expected := [][]string{
+					{"retrying\n    ", "main.main\n    ", "/home/nick/main.go:12\n"},
+					{Dropped, Dropped, Dropped},
+				}
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "reset",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewGlobalDedupeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "things broke :(", formatter.FormatTrace(nil, "things broke :("))
+				formatter.(Resettable).Reset()
+				assert.Equal(t, "things broke :(", formatter.FormatTrace(nil, "things broke :("))
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestGlobalDedupeFormatter_DetailedTrace(t *testing.T) {
+	formatter, err := NewGlobalDedupeFormatter()
+	assert.Nil(t, err)
+
+	baseErr := xerrors.Errorf("retrying: %w", xerrors.Errorf("aw shucks: %w", xerrors.New("retrying")))
+	tracer, err := NewTracer(baseErr, Formatter(formatter), DetailSeparator("\n"))
+	assert.Nil(t, err)
+
+	output := fmt.Sprintf("%+v", tracer)
+	assert.Equal(t, 1, strings.Count(output, "(repeated)"), output)
+	assert.True(t, strings.HasPrefix(output, "retrying\n"), output)
+	assert.Contains(t, output, "retrying (repeated)\n")
+}
+
+func TestGlobalDedupeFormatter_SuppressedTrace(t *testing.T) {
+	formatter, err := NewGlobalDedupeFormatter(SuppressDuplicates(true))
+	assert.Nil(t, err)
+
+	innerErr := xerrors.Errorf("retrying: %w", errors.New("boom"))
+	baseErr := xerrors.Errorf("retrying: %w", xerrors.Errorf("boom: %w", innerErr))
+	output := traceWithFormatter(t, formatter, baseErr, DetailedOutput(false))
+	assert.Equal(t, "boom\nretrying", output)
+}
+
+func TestTrimWrappedPrefixFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "fmt.Errorf style chain",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewTrimWrappedPrefixFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := []string{}
+				messages := []string{
+					"things broke :(",
+					"aw shucks: things broke :(",
+					"I tried very hard and failed: aw shucks: things broke :(",
+				}
+				for _, message := range messages {
+					output = append(output, formatter.FormatTrace(nil, message))
+				}
+
+				assert.Equal(t, []string{"things broke :(", "aw shucks", "I tried very hard and failed"}, output)
+			},
+		},
+		{
+			name: "xerrors.Errorf style chain",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewTrimWrappedPrefixFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := []string{}
+				messages := []string{
+					"things broke :(",
+					"aw shucks",
+					"I tried very hard and failed",
+				}
+				for _, message := range messages {
+					output = append(output, formatter.FormatTrace(nil, message))
+				}
+
+				assert.Equal(t, messages, output)
+			},
+		},
+		{
+			name: "no common suffix",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewTrimWrappedPrefixFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "things broke :(", formatter.FormatTrace(nil, "things broke :("))
+				assert.Equal(t, "aw shucks: it broke", formatter.FormatTrace(nil, "aw shucks: it broke"))
+				assert.Equal(t, "aw shucks: it broke", formatter.FormatTrace(nil, "aw shucks: it broke"))
+			},
+		},
+		{
+			name: "details are untouched",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewTrimWrappedPrefixFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "things broke :(", formatter.FormatTrace(nil, "things broke :("))
+				output := formatter.FormatTrace([]string{"aw shucks"}, "\n    main.go:10: things broke :(")
+				assert.Equal(t, "\n    main.go:10: things broke :(", output)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestBulletFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "cycles bullets",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewBulletFormatter(Bullets([]string{"* ", "- "}))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{
+					"things broke :(",
+					"main.main",
+					"main.doThings",
+					"main.doOtherThings",
+					"main.doEvenMoreThings",
+				}
+				for _, message := range messages {
+					trace = append(trace, formatter.FormatTrace(trace, message))
+				}
+
+				expected := []string{
+					"things broke :(",
+					"* main.main",
+					"- main.doThings",
+					"* main.doOtherThings",
+					"- main.doEvenMoreThings",
+				}
+				assert.Equal(t, expected, trace)
+			},
+		},
+		{
+			name: "wrapped around NestedMessageFormatter",
+			setup: func(t *testing.T) TraceFormatter {
+				nestedFormatter, err := NewNestedMessageFormatter(NestingIndentation("  "))
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewBulletFormatter(BulletInnerFormatter(nestedFormatter))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"things broke :(", "main.main", "main.doThings"}
+				for _, message := range messages {
+					trace = append(trace, formatter.FormatTrace(trace, message))
+				}
+
+				assert.Equal(t, []string{"things broke :(\n", "  • main.main\n", "  ◦ main.doThings"}, trace)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestNewBulletFormatter_NoBullets(t *testing.T) {
+	_, err := NewBulletFormatter(Bullets(nil))
+	assert.NotNil(t, err)
+}
+
+func TestHyperlinkFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "default template",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewHyperlinkFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace([]string{"aw shucks"}, "main.main\n    /home/nick/main.go:12\n")
+				expected := "main.main\n    " +
+					"\x1b]8;;file:///home/nick/main.go\x1b\\/home/nick/main.go:12\x1b]8;;\x1b\\\n"
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "custom template, nested",
+			setup: func(t *testing.T) TraceFormatter {
+				nestedFormatter, err := NewNestedMessageFormatter()
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewHyperlinkFormatter(
+					HyperlinkURL("vscode://file{file}:{line}"),
+					HyperlinkInnerFormatter(nestedFormatter),
+				)
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, "main.main\n    /home/nick/main.go:12\n")
+				expected := "main.main\n\t" +
+					"\x1b]8;;vscode://file/home/nick/main.go:12\x1b\\/home/nick/main.go:12\x1b]8;;\x1b\\"
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "no references",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewHyperlinkFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, "aw shucks: 12 things broke")
+				assert.Equal(t, "aw shucks: 12 things broke", output)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestNewHyperlinkFormatter_InvalidTemplate(t *testing.T) {
+	_, err := NewHyperlinkFormatter(HyperlinkURL("vscode://file"))
+	assert.NotNil(t, err)
+}
+
+func TestDetailColorFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "default styles",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewDetailColorFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"aw shucks\n", "main.main\n    /home/nick/main.go:12\n"}
+				for _, message := range messages {
+					trace = append(trace, formatter.FormatTrace(trace, message))
+				}
+
+				expected := []string{
+					"\x1b[1maw shucks\x1b[0m\n",
+					"\x1b[2mmain.main\x1b[0m\n    \x1b[2m/home/nick/main.go:12\x1b[0m\n",
+				}
+				assert.Equal(t, expected, trace)
+			},
+		},
+		{
+			name: "custom styles, nested",
+			setup: func(t *testing.T) TraceFormatter {
+				nestedFormatter, err := NewNestedMessageFormatter()
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewDetailColorFormatter(
+					MessageStyle("\x1b[31m"),
+					FrameStyle("\x1b[34m"),
+					DetailColorInnerFormatter(nestedFormatter),
+				)
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				trace := []string{}
+				messages := []string{"aw shucks", "main.main\n    /home/nick/main.go:12\n"}
+				for _, message := range messages {
+					trace = append(trace, formatter.FormatTrace(trace, message))
+				}
+
+				output := strings.Join(trace, "")
+				assert.Equal(t, strings.Count(output, "\x1b[31m")+strings.Count(output, "\x1b[34m"),
+					strings.Count(output, "\x1b[0m"))
+				assert.Equal(t, "\x1b[31maw shucks\x1b[0m\n", trace[0])
+				assert.True(t, strings.HasPrefix(trace[1], "\t"))
+			},
+		},
+		{
+			name: "disabled",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewDetailColorFormatter(DetailColorEnabled(false))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace([]string{"aw shucks"}, "main.main\n    /home/nick/main.go:12\n")
+				assert.Equal(t, "main.main\n    /home/nick/main.go:12\n", output)
+			},
+		},
+		{
+			name: "traced",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewDetailColorFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := traceWithFormatter(t, formatter, Errorf("aw shucks: %w", errors.New("things broke :(")))
+				openCount := strings.Count(output, "\x1b[1m") + strings.Count(output, "\x1b[2m")
+				assert.Equal(t, openCount, strings.Count(output, "\x1b[0m"))
+				assert.Contains(t, output, "\x1b[1maw shucks\x1b[0m")
+				assert.Contains(t, output, "\x1b[2m")
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestControlEscapeFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "control characters",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewControlEscapeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, "things \x1b[2Jbroke\x00 ☹\n\tagain \xff\u200b")
+				assert.Equal(t, `things \x1b[2Jbroke\x00 ☹`+"\n\t"+`again \xff\u200b`, output)
+			},
+		},
+		{
+			name: "escaped newlines, nested",
+			setup: func(t *testing.T) TraceFormatter {
+				nestedFormatter, err := NewNestedMessageFormatter()
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewControlEscapeFormatter(
+					EscapeNewlines(true),
+					ControlEscapeInnerFormatter(nestedFormatter),
+				)
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				previousMessages := []string{"aw shucks"}
+				output := formatter.FormatTrace(previousMessages, "things\nbroke\x00")
+				assert.Equal(t, `	things\nbroke\x00`, output)
+				assert.Equal(t, "aw shucks\n", previousMessages[0])
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestNewControlEscapeFormatter_NilInner(t *testing.T) {
+	_, err := NewControlEscapeFormatter(ControlEscapeInnerFormatter(nil))
+	assert.NotNil(t, err)
+}
+
+func TestMarkdownOrderedFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "numbering and nesting",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewMarkdownOrderedFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				contextual := formatter.(ContextualTraceFormatter)
+				output := []string{}
+				for i, message := range []string{"things broke :(", "aw *shucks*"} {
+					output = append(output, contextual.FormatTraceWithContext(TraceContext{Index: i}, nil, message))
+				}
+
+				trace := []string{output[1]}
+				detail := "main.main\n    /home/nick/main.go:12\n"
+				output[1] += contextual.FormatTraceWithContext(TraceContext{Index: 1}, trace, detail)
+
+				expected := []string{
+					`1. things broke :\(`,
+					`2. aw \*shucks\*` + "\n" + `   - main\.main` + "\n" + `   - /home/nick/main\.go:12`,
+				}
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "numbering restarts for each group",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewMarkdownOrderedFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				contextual := formatter.(ContextualTraceFormatter)
+				output := []string{
+					contextual.FormatTraceWithContext(TraceContext{Group: 0}, nil, "things broke"),
+					contextual.FormatTraceWithContext(TraceContext{Group: 0}, nil, "aw shucks"),
+					contextual.FormatTraceWithContext(TraceContext{Group: 1}, nil, "oh no"),
+				}
+
+				assert.Equal(t, []string{"1. things broke", "2. aw shucks", "1. oh no"}, output)
+			},
+		},
+		{
+			name: "reset",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewMarkdownOrderedFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "1. things broke", formatter.FormatTrace(nil, "things broke"))
+				formatter.(Resettable).Reset()
+				assert.Equal(t, "1. aw shucks", formatter.FormatTrace(nil, "aw shucks"))
+			},
+		},
+		{
+			name: "traced with groups, twice",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewMarkdownOrderedFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				errs := []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")), errors.New("oh no")}
+				tracer, err := NewMultiTracer(errs, DetailedOutput(false), Formatter(formatter), GroupSeparator("\n"))
+				assert.Nil(t, err)
+
+				// The formatter must be reset between full traces.
+				expected := "1. things broke :\\(\n2. aw shucks\n\n1. oh no"
+				for i := 0; i < 2; i++ {
+					assert.Equal(t, expected, fmt.Sprintf("%v", tracer))
+				}
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestCodeFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "identical messages",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewCodeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "[E-D541FFAB] things broke", formatter.FormatTrace(nil, "things broke"))
+				assert.Equal(t, "[E-D541FFAB] things broke", formatter.FormatTrace(nil, "things broke"))
+				assert.NotEqual(t, "[E-D541FFAB] aw shucks", formatter.FormatTrace(nil, "aw shucks"))
+			},
+		},
+		{
+			name: "volatile details",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewCodeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				expected := []string{
+					"[E-E3882904] could not open /tmp/foo.txt after 3 tries",
+					"[E-E3882904] could not open C:\\bar  after 12 tries ",
+				}
+				output := []string{
+					formatter.FormatTrace(nil, "could not open /tmp/foo.txt after 3 tries"),
+					formatter.FormatTrace(nil, "could not open C:\\bar  after 12 tries "),
+				}
+
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "detail is left as is",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewCodeFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				detail := "main.main\n    /home/nick/main.go:12\n"
+				assert.Equal(t, detail, formatter.FormatTrace([]string{"things broke"}, detail))
+			},
+		},
+		{
+			name: "code follows inner formatter's indentation",
+			setup: func(t *testing.T) TraceFormatter {
+				nested, err := NewNestedMessageFormatter(IndentByDepth(true))
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewCodeFormatter(CodeInnerFormatter(nested))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				contextual := formatter.(ContextualTraceFormatter)
+				output := contextual.FormatTraceWithContext(TraceContext{ChainLength: 2}, nil, "things broke")
+				assert.Equal(t, "\t[E-D541FFAB] things broke", output)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestCodeFormatter_Tracer(t *testing.T) {
+	formatter, err := NewCodeFormatter()
+	assert.Nil(t, err)
+
+	err1 := errors.New("things broke")
+	err2 := xerrors.Errorf("attempt 1 failed: %w", err1)
+	baseErr := xerrors.Errorf("attempt 2 failed: %w", err2)
+	tracer, err := NewTracer(baseErr, DetailedOutput(false), Formatter(formatter))
+	assert.Nil(t, err)
+
+	lines := strings.Split(fmt.Sprintf("%v", tracer), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, "[E-D541FFAB] things broke", lines[0])
+	assert.Equal(t, lines[1][:len("[E-00000000]")], lines[2][:len("[E-00000000]")])
+	assert.Equal(t, " attempt 1 failed", lines[1][len("[E-00000000]"):])
+}
+
+func TestRFC5424Formatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "escaping",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewRFC5424Formatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, `things "broke" in C:\tmp [again]`)
+				assert.Equal(t, `[xtrace depth="0" msg="things \"broke\" in C:\\tmp [again\]"]`, output)
+			},
+		},
+		{
+			name: "invalid UTF-8",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewRFC5424Formatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := formatter.FormatTrace(nil, "things broke \xff")
+				assert.Equal(t, "[xtrace depth=\"0\" msg=\"things broke \uFFFD\"]", output)
+			},
+		},
+		{
+			name: "detail",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewRFC5424Formatter(StructuredDataID("xtrace@32473"))
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				contextual := formatter.(ContextualTraceFormatter)
+				context := TraceContext{Depth: 1}
+				messages := []string{contextual.FormatTraceWithContext(context, nil, "aw shucks")}
+				messages = append(messages, contextual.FormatTraceWithContext(context, messages, "main.main\n    "))
+				messages = append(
+					messages,
+					contextual.FormatTraceWithContext(context, messages, "/home/nick/main.go:12\n"),
+				)
+
+				expected := `[xtrace@32473 depth="1" msg="aw shucks" detail="main.main" detail="/home/nick/main.go:12"]`
+				assert.Equal(t, expected, strings.Join(messages, ""))
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestStructuredDataID_Invalid(t *testing.T) {
+	for _, id := range []string{"", "x trace", "x=trace", "xtrace]", `"xtrace"`, "xträce", strings.Repeat("x", 33)} {
+		_, err := NewRFC5424Formatter(StructuredDataID(id))
+		assert.NotNil(t, err, id)
+	}
+}
+
+func TestYAMLFormatter_Scalars(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "things broke :(", expected: "things broke :("},
+		{message: "aw shucks: things broke", expected: `"aw shucks: things broke"`},
+		{message: "null", expected: `"null"`},
+		{message: "404 not found", expected: `"404 not found"`},
+		{message: "- things broke", expected: `"- things broke"`},
+		{message: "things \"broke\" #1", expected: `"things \"broke\" #1"`},
+		{message: "   ", expected: `""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			output := NewYAMLFormatter().FormatTrace(nil, tt.message)
+			assert.Equal(t, "- depth: 0\n  message: "+tt.expected, output)
+		})
+	}
+}
+
+func TestColumnarFormatter(t *testing.T) {
+	err1 := errors.New("things broke :(")
+	// Create the error in a closure, so that the function names differ in length.
+	err2 := func() error {
+		return xerrors.Errorf("aw shucks: %w", err1)
+	}()
+	err3 := Errorf("oh no, this is a much longer message: %w", err2)
+	tracer, err := NewTracer(err3, Formatter(NewColumnarFormatter()))
+	assert.Nil(t, err)
+
+	lines := strings.Split(fmt.Sprintf("%+v", tracer), "\n")
+	assert.Equal(t, 3, len(lines), lines)
+	assert.Equal(t, "things broke :(", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "aw shucks  "), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "oh no, this is a much longer message  "), lines[2])
+
+	// Both the function and location columns should start at the same position in each line with a location.
+	functionColumn := strings.Index(lines[2], "github.com/ollien/xtrace.TestColumnarFormatter")
+	assert.Equal(t, functionColumn, strings.Index(lines[1], "github.com/ollien/xtrace.TestColumnarFormatter"))
+	assert.Equal(t, strings.Index(lines[2], " /"), strings.Index(lines[1], " /"))
+}
+
+func TestColumnarFormatter_NoDetail(t *testing.T) {
+	err1 := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err1)
+	tracer, err := NewTracer(err2, Formatter(NewColumnarFormatter()))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "things broke :(\naw shucks", fmt.Sprintf("%v", tracer))
+}
+
+func TestColumnarFormatter_NonComparableError(t *testing.T) {
+	// A joinedError can not be used as a map key, so the rows must not be looked up by the error itself.
+	joined := joinedError{errors.New("things broke :("), errors.New("an awful thing happened")}
+	tracer, err := NewTracer(xerrors.Errorf("aw shucks: %w", joined), Formatter(NewColumnarFormatter()))
+	assert.Nil(t, err)
+
+	lines := strings.Split(fmt.Sprintf("%+v", tracer), "\n")
+	assert.Equal(t, 2, len(lines), lines)
+	assert.Equal(t, "things broke :( an awful thing happened", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "aw shucks  "), lines[1])
+}
+
+func TestColumnarFormatter_Reset(t *testing.T) {
+	formatter := NewColumnarFormatter()
+	err1 := Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, err := NewTracer(err1, Formatter(formatter))
+	assert.Nil(t, err)
+	assert.Contains(t, fmt.Sprintf("%+v", tracer), "aw shucks  ")
+
+	// Another chain of the same length must not be given the rows of the first.
+	err2 := Errorf("oh no: %w", errors.New("an awful thing happened"))
+	tracer, err = NewTracer(err2, Formatter(formatter))
+	assert.Nil(t, err)
+
+	output := fmt.Sprintf("%+v", tracer)
+	assert.Contains(t, output, "oh no  ")
+	assert.NotContains(t, output, "aw shucks")
+}
+
+func TestRootHighlightFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "oldest first",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewRootHighlightFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				output := traceWithFormatter(t, formatter, err, DetailedOutput(false))
+				assert.Equal(t, "[root] things broke :(\naw shucks", output)
+			},
+		},
+		{
+			name: "newest first, custom marker, nested",
+			setup: func(t *testing.T) TraceFormatter {
+				nestedFormatter, err := NewNestedMessageFormatter()
+				if err != nil {
+					return handleFormatTestSetupError(t, nil, err)
+				}
+
+				formatter, err := NewRootHighlightFormatter(
+					RootMarker("=> "),
+					RootHighlightInnerFormatter(nestedFormatter),
+				)
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				output := traceWithFormatter(t, formatter, err3, DetailedOutput(false), Ordering(NewestFirstOrdering))
+				assert.Equal(t, "I tried very hard and failed\naw shucks\n=> things broke :(", output)
+			},
+		},
+		{
+			name: "without context",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewRootHighlightFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				assert.Equal(t, "things broke :(", formatter.FormatTrace(nil, "things broke :("))
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestYAMLFormatter(t *testing.T) {
+	tests := []formatTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewYAMLFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("true: %w", err2)
+				output := traceWithFormatter(t, formatter, err3, DetailedOutput(false), Ordering(NewestFirstOrdering))
+				expected := "- depth: 2\n  message: \"true\"\n" +
+					"- depth: 1\n  message: aw shucks\n" +
+					"- depth: 0\n  message: things broke :("
+				assert.Equal(t, expected, output)
+			},
+		},
+		{
+			name: "detailed output",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewYAMLFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := traceWithFormatter(t, formatter, xerrors.New("things broke :("), DetailedOutput(true))
+				expectedPattern := `^- depth: 0\n  message: \|-\n    things broke :\(\n` +
+					`    github\.com/ollien/xtrace\.TestYAMLFormatter\.func\d+\n    \S+/format_test\.go:\d+$`
+				assert.Regexp(t, expectedPattern, output)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestCollapseCounterFormatter(t *testing.T) {
+	retriedErr := func() error {
+		err := errors.New("things broke :(")
+		for i := 1; i <= 3; i++ {
+			err = xerrors.Errorf("attempt %d failed on port 8080: %w", i, err)
+		}
+
+		return xerrors.Errorf("aw shucks: %w", err)
+	}
+
+	tests := []formatTest{
+		{
+			name: "oldest first",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewCollapseCounterFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := traceWithFormatter(t, formatter, retriedErr(), DetailedOutput(false))
+				assert.Equal(t, "things broke :(\nattempt N failed on port 8080 (x3)\naw shucks", output)
+			},
+		},
+		{
+			name: "newest first",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewCollapseCounterFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				output := traceWithFormatter(
+					t,
+					formatter,
+					retriedErr(),
+					DetailedOutput(false),
+					Ordering(NewestFirstOrdering),
+				)
+				assert.Equal(t, "aw shucks\nattempt N failed on port 8080 (x3)\nthings broke :(", output)
+			},
+		},
+		{
+			name: "no run",
+			setup: func(t *testing.T) TraceFormatter {
+				return NewCollapseCounterFormatter()
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				err := xerrors.Errorf("attempt 2 failed: %w", errors.New("attempt 1 broke"))
+				output := traceWithFormatter(t, formatter, err, DetailedOutput(false))
+				assert.Equal(t, "attempt 1 broke\nattempt 2 failed", output)
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestSummaryFormatter(t *testing.T) {
+	// The formatter must be reset between full traces, so each tracer is traced twice.
+	assertTracedTwice := func(t *testing.T, tracer *Tracer, expected string) {
+		output := bytes.NewBufferString("")
+		assert.Nil(t, tracer.Trace(output))
+		assert.Equal(t, expected, output.String())
+		assert.Equal(t, expected, fmt.Sprintf("%v", tracer))
+	}
+
+	tests := []formatTest{
+		{
+			name: "oldest first",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewSummaryFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				baseErr := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")))
+				tracer, err := NewTracer(baseErr, DetailedOutput(false), Formatter(formatter))
+				assert.Nil(t, err)
+				assertTracedTwice(t, tracer, "things broke :(: oh no\nthings broke :(\naw shucks\noh no")
+			},
+		},
+		{
+			name: "newest first",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewSummaryFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				baseErr := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")))
+				tracer, err := NewTracer(
+					baseErr,
+					DetailedOutput(false),
+					Formatter(formatter),
+					Ordering(NewestFirstOrdering),
+				)
+				assert.Nil(t, err)
+				assertTracedTwice(t, tracer, "things broke :(: oh no\noh no\naw shucks\nthings broke :(")
+			},
+		},
+		{
+			name: "multiple errors",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewSummaryFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				errs := []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")), errors.New("oh no")}
+				tracer, err := NewMultiTracer(errs, DetailedOutput(false), Formatter(formatter))
+				assert.Nil(t, err)
+				assertTracedTwice(t, tracer, "things broke :(: aw shucks\nthings broke :(\naw shucks\noh no\noh no")
+			},
+		},
+		{
+			name: "default inner formatter",
+			setup: func(t *testing.T) TraceFormatter {
+				formatter, err := NewSummaryFormatter()
+
+				return handleFormatTestSetupError(t, formatter, err)
+			},
+			testFunc: func(t *testing.T, formatter TraceFormatter) {
+				// Like the other decorators, messages are left as is unless an inner formatter is given.
+				assert.Equal(t, "main.main\n", formatter.FormatTrace([]string{"aw shucks\n"}, "main.main\n"))
+			},
+		},
+	}
+
+	runFormatTestTable(t, tests)
+}
+
+func TestNarrativeFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []func(*Tracer) error
+		narrated []func(*NarrativeFormatter) error
+		expected string
+	}{
+		{
+			name:     "oldest first",
+			options:  []func(*Tracer) error{Ordering(OldestFirstOrdering)},
+			expected: "Because: things broke\nWhich caused: aw shucks\nWhich caused: oh no",
+		},
+		{
+			name:     "newest first",
+			options:  []func(*Tracer) error{Ordering(NewestFirstOrdering)},
+			expected: "Which caused: oh no\nWhich caused: aw shucks\nBecause: things broke",
+		},
+		{
+			name:     "connectives",
+			narrated: []func(*NarrativeFormatter) error{Connectives("Root cause:", "Led to:")},
+			expected: "Root cause: things broke\nLed to: aw shucks\nLed to: oh no",
+		},
+		{
+			name:     "localized messages",
+			options:  []func(*Tracer) error{WithMessages(Messages{Because: "Porque:", WhichCaused: "Lo que causó:"})},
+			expected: "Porque: things broke\nLo que causó: aw shucks\nLo que causó: oh no",
+		},
+		{
+			name:     "partially localized messages",
+			options:  []func(*Tracer) error{WithMessages(Messages{Because: "Porque:"})},
+			expected: "Porque: things broke\nWhich caused: aw shucks\nWhich caused: oh no",
+		},
+		{
+			name:     "connectives override messages",
+			options:  []func(*Tracer) error{WithMessages(Messages{Because: "Porque:", WhichCaused: "Lo que causó:"})},
+			narrated: []func(*NarrativeFormatter) error{Connectives("Root cause:", "Led to:")},
+			expected: "Root cause: things broke\nLed to: aw shucks\nLed to: oh no",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewNarrativeFormatter(tt.narrated...)
+			assert.Nil(t, err)
+
+			err1 := errors.New("things broke")
+			err2 := xerrors.Errorf("aw shucks: %w", err1)
+			err3 := xerrors.Errorf("oh no: %w", err2)
+			options := append([]func(*Tracer) error{DetailedOutput(false), Formatter(formatter)}, tt.options...)
+			tracer, err := NewTracer(err3, options...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%v", tracer))
+		})
+	}
+}
+
+func TestDOTFormatter(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New(`things "broke" :(`)
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, Formatter(NewDOTFormatter()))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+
+				expected := "digraph trace {\n" +
+					"\terr0_0 [label=\"things \\\"broke\\\" :(\"];\n" +
+					"\terr0_1 [label=\"aw shucks\"];\n" +
+					"\terr0_1 -> err0_0;\n" +
+					"}"
+				assert.Equal(t, expected, buffer.String())
+			},
+		},
+		{
+			name: "independent errors",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				err2 := errors.New("an awful thing happened")
+				tracer, constructErr := NewMultiTracer(
+					[]error{err, err2},
+					DetailedOutput(false),
+					Ordering(NewestFirstOrdering),
+					Formatter(NewDOTFormatter()),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%v", tracer)
+				expected := "digraph trace {\n" +
+					"\terr0_1 [label=\"aw shucks\"];\n" +
+					"\terr0_1 -> err0_0;\n" +
+					"\terr0_0 [label=\"things broke :(\"];\n" +
+					"\terr1_0 [label=\"an awful thing happened\"];\n" +
+					"}"
+				assert.Equal(t, expected, out)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTimingFormatter(t *testing.T) {
+	start := time.Date(2019, time.December, 1, 0, 0, 0, 0, time.UTC)
+	err := timestampedError{message: "things broke :(", timestamp: start}
+	err2 := timestampedError{message: "aw shucks", timestamp: start.Add(12 * time.Millisecond), wrapped: err}
+	err3 := xerrors.Errorf("retrying: %w", err2)
+	err4 := timestampedError{
+		message:   "I tried very hard and failed",
+		timestamp: start.Add(2 * time.Second),
+		wrapped:   err3,
+	}
+
+	tracer, constructErr := NewTracer(err4, DetailedOutput(false), Formatter(NewTimingFormatter()))
+	assert.Nil(t, constructErr)
+
+	out := fmt.Sprintf("%v", tracer)
+	expected := "[+0s] things broke :(\n[+12ms] aw shucks\nretrying\n[+1.988s] I tried very hard and failed"
+	assert.Equal(t, expected, out)
+
+	// Traces should be repeatable, as the formatter is reset
+	assert.Equal(t, expected, fmt.Sprintf("%v", tracer))
+}
+
+func TestWhenFormatter(t *testing.T) {
+	start := time.Date(2019, time.December, 1, 0, 0, 0, 0, time.UTC)
+	err := whenError{message: "things broke :(", when: start}
+	err2 := xerrors.Errorf("aw shucks: %w", err)
+	err3 := whenError{message: "I tried very hard and failed", when: start.Add(1500 * time.Millisecond), wrapped: err2}
+
+	tests := []struct {
+		name     string
+		err      error
+		options  []func(*WhenFormatter) error
+		ordering TraceOrderingMethod
+		expected string
+	}{
+		{
+			name:     "mixed chain",
+			err:      err3,
+			ordering: OldestFirstOrdering,
+			expected: "[2019-12-01T00:00:00Z +0s] things broke :(\naw shucks\n" +
+				"[2019-12-01T00:00:01.5Z +1.5s] I tried very hard and failed",
+		},
+		{
+			name:     "newest first",
+			err:      err3,
+			ordering: NewestFirstOrdering,
+			expected: "[2019-12-01T00:00:01.5Z +1.5s] I tried very hard and failed\naw shucks\n" +
+				"[2019-12-01T00:00:00Z +0s] things broke :(",
+		},
+		{
+			name:     "custom layout",
+			err:      err3,
+			options:  []func(*WhenFormatter) error{WhenLayout("15:04:05.000")},
+			ordering: OldestFirstOrdering,
+			expected: "[00:00:00.000 +0s] things broke :(\naw shucks\n" +
+				"[00:00:01.500 +1.5s] I tried very hard and failed",
+		},
+		{
+			name:     "root without time",
+			err:      whenError{message: "aw shucks", when: start, wrapped: errors.New("things broke :(")},
+			ordering: OldestFirstOrdering,
+			expected: "things broke :(\n[2019-12-01T00:00:00Z +0s] aw shucks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newLineFormatter, err := NewNewLineFormatter()
+			assert.Nil(t, err)
+
+			options := append([]func(*WhenFormatter) error{WhenInnerFormatter(newLineFormatter)}, tt.options...)
+			formatter, err := NewWhenFormatter(options...)
+			assert.Nil(t, err)
+
+			tracer, err := NewTracer(tt.err, DetailedOutput(false), Formatter(formatter), Ordering(tt.ordering))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%v", tracer))
+		})
+	}
+}
+
+func TestWhenFormatter_InvalidOptions(t *testing.T) {
+	_, err := NewWhenFormatter(WhenLayout(""))
+	assert.NotNil(t, err)
+
+	_, err = NewWhenFormatter(WhenInnerFormatter(nil))
+	assert.NotNil(t, err)
+}
@@ -16,14 +16,116 @@ package xtrace
    limitations under the License.
 */
 
+import (
+	"errors"
+	"strings"
+)
+
+// TrimStrategyMethod represents an algorithm that a NewLineFormatter may use to strip the newlines from the end of each
+// message, and to restore them once another message follows it.
+type TrimStrategyMethod int
+
+const (
+	// TrimRegex strips the newlines from the whitespace that follows the content of each message, using a regular
+	// expression, so that the indentation that xerrors sends at the end of the previous message (i.e. "<error>\n    ")
+	// is handled (default).
+	TrimRegex TrimStrategyMethod = iota
+	// TrimSimple strips the newlines from the very end of each message, and nothing else. This is faster than
+	// TrimRegex, but produces weird output when messages end in a newline followed by other whitespace.
+	TrimSimple
+	// TrimNone leaves each message as is, only adding a newline to the end of a message that does not already end in
+	// one once another message follows it. This is the fastest, but is only suitable for messages that never end in
+	// whitespace.
+	TrimNone
+)
+
+// trimStrategyForNaive maps the naive flag onto the TrimStrategyMethod that it is equivalent to.
+func trimStrategyForNaive(naive bool) TrimStrategyMethod {
+	if naive {
+		return TrimSimple
+	}
+
+	return TrimRegex
+}
+
 // Naive will set the naive flag when passed to NewNewLineFormatter. This flag, if set, will instruct the formatter
 // to perform the naive version of this algorithm, which simply adds/removes a newline from the end of each message.
 // xerrors has a habit of sending indentation in the previous line (i.e. "<error>\n    "), so the naive algorithm
 // produces weird output. Nevertheless, this may be desirable depending on the implementation of xerrors.Formatter, so
-// it is left as an option. Defaults to false.
+// it is left as an option. This is equivalent to TrimStrategy(TrimSimple) if set, and TrimStrategy(TrimRegex)
+// otherwise. Defaults to false.
 func Naive(naive bool) func(*NewLineFormatter) error {
 	return func(formatter *NewLineFormatter) error {
-		formatter.naive = naive
+		formatter.trimStrategy = trimStrategyForNaive(naive)
+
+		return nil
+	}
+}
+
+// TrimStrategy sets the algorithm that the NewLineFormatter produced when this is passed to NewNewLineFormatter will
+// use to strip and restore the newlines at the end of each message. This is a more explicit form of Naive, which also
+// allows trimming to be skipped entirely. Defaults to TrimRegex.
+func TrimStrategy(method TrimStrategyMethod) func(*NewLineFormatter) error {
+	return func(formatter *NewLineFormatter) error {
+		if method != TrimRegex && method != TrimSimple && method != TrimNone {
+			return errors.New("invalid trim strategy provided to NewLineFormatter")
+		}
+
+		formatter.trimStrategy = method
+
+		return nil
+	}
+}
+
+// PreserveIndent will set the preserveIndent flag when passed to NewNewLineFormatter. By default, the TrimRegex
+// strategy only considers the first line of each message, so a message that spans multiple lines, such as detailed
+// output whose frame lines are indented, loses everything after its first line if no message follows it. This flag, if
+// set, will instruct the formatter to leave every line of the message, including its leading indentation, as is, and
+// to only strip the newlines from the whitespace after the last of its content. This has no effect on the other trim
+// strategies, which never touch anything but the end of each message. Defaults to false.
+func PreserveIndent(preserve bool) func(*NewLineFormatter) error {
+	return func(formatter *NewLineFormatter) error {
+		formatter.preserveIndent = preserve
+
+		return nil
+	}
+}
+
+// CollapseBlankLines will set the collapseBlankLines flag when passed to NewNewLineFormatter. This flag, if set, will
+// instruct the formatter to squeeze any run of consecutive blank lines within a message into a single blank line, which
+// tidies up verbose detailed output. Defaults to false.
+func CollapseBlankLines(collapse bool) func(*NewLineFormatter) error {
+	return func(formatter *NewLineFormatter) error {
+		formatter.collapseBlankLines = collapse
+
+		return nil
+	}
+}
+
+// NormalizeWhitespace will set the normalizeWhitespace flag when passed to NewNewLineFormatter. This flag, if set,
+// will instruct the formatter to collapse each run of spaces and tabs within a line of a message to a single space,
+// before any newlines are handled. Whitespace at the start and end of each line, such as the indentation of detailed
+// output, is preserved, as are newlines. Defaults to false.
+func NormalizeWhitespace(normalize bool) func(*NewLineFormatter) error {
+	return func(formatter *NewLineFormatter) error {
+		formatter.normalizeWhitespace = normalize
+
+		return nil
+	}
+}
+
+// LineEnding sets the line ending that the NewLineFormatter produced when this is passed to NewNewLineFormatter will
+// write. Regardless of this setting, "\r\n" and lone "\r" line endings in messages are treated as line endings, and all
+// line endings within each formatted message are normalized to the given one. A Tracer using the formatter writes the
+// full trace with the same line ending, including the newlines between errors, as described by LineEndingFormatter.
+// Only "\n" and "\r\n" are supported. Defaults to "\n".
+func LineEnding(ending string) func(*NewLineFormatter) error {
+	return func(formatter *NewLineFormatter) error {
+		if ending != "\n" && ending != "\r\n" {
+			return errors.New(`line ending must be either "\n" or "\r\n"`)
+		}
+
+		formatter.lineEnding = ending
 
 		return nil
 	}
@@ -38,3 +140,306 @@ func NestingIndentation(indentation string) func(*NestedMessageFormatter) error
 		return nil
 	}
 }
+
+// IndentToMatch sets a function that computes the indentation of each indented line for the NestedMessageFormatter
+// that is produced when this is passed to NewNestedMessageFormatter, in place of the static indentation set by
+// NestingIndentation. The function is called once for each non-empty line that is indented, and is given the formatted
+// line before it, without its newline; this is the previous line of the same message, or, for the first line of a
+// message, the last line of the message before it. The line before it will have already been indented, which allows
+// for hanging indentation that aligns with a prefix of the first line. Defaults to using the static indentation.
+func IndentToMatch(indentFunc func(previous string) string) func(*NestedMessageFormatter) error {
+	return func(formatter *NestedMessageFormatter) error {
+		if indentFunc == nil {
+			return errors.New("nil indentation function provided to NestedMessageFormatter")
+		}
+
+		formatter.indentFunc = indentFunc
+
+		return nil
+	}
+}
+
+// IndentByDepth will set the indentByDepth flag when passed to NewNestedMessageFormatter. This flag, if set, will
+// indent each error by its depth within its chain, so that the error that wraps all others is not indented, and the
+// root cause is indented the most. As the depth of an error is anchored at the root cause (see TraceContext), this does
+// not depend on the Tracer's ordering; display order and indentation are two independent knobs, so NewestFirstOrdering
+// can be used to put the most recent context on top while still indenting towards the root cause. This relies on the
+// context of each error, so it has no effect when the formatter is used through FormatTrace. Defaults to false.
+func IndentByDepth(enabled bool) func(*NestedMessageFormatter) error {
+	return func(formatter *NestedMessageFormatter) error {
+		formatter.indentByDepth = enabled
+
+		return nil
+	}
+}
+
+// SuppressDuplicates will drop errors with repeated messages from the output entirely, along with their detailed
+// output, rather than annotating them, when passed to NewGlobalDedupeFormatter. Defaults to false.
+func SuppressDuplicates(suppress bool) func(*GlobalDedupeFormatter) error {
+	return func(formatter *GlobalDedupeFormatter) error {
+		formatter.suppress = suppress
+
+		return nil
+	}
+}
+
+// Bullets sets the bullets that the BulletFormatter produced when this is passed to NewBulletFormatter will cycle
+// through. At least one bullet must be given. Defaults to "• " and "◦ ".
+func Bullets(bullets []string) func(*BulletFormatter) error {
+	return func(formatter *BulletFormatter) error {
+		if len(bullets) == 0 {
+			return errors.New("at least one bullet must be provided to BulletFormatter")
+		}
+
+		formatter.bullets = bullets
+
+		return nil
+	}
+}
+
+// BulletInnerFormatter sets the formatter that each message is passed to before a bullet is inserted, for the
+// BulletFormatter produced when this is passed to NewBulletFormatter. Defaults to NilFormatter.
+func BulletInnerFormatter(inner TraceFormatter) func(*BulletFormatter) error {
+	return func(formatter *BulletFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to BulletFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// RootMarker sets the marker that the RootHighlightFormatter produced when this is passed to NewRootHighlightFormatter
+// will prefix the root cause with. Defaults to "[root] ".
+func RootMarker(marker string) func(*RootHighlightFormatter) error {
+	return func(formatter *RootHighlightFormatter) error {
+		formatter.marker = marker
+
+		return nil
+	}
+}
+
+// RootHighlightInnerFormatter sets the formatter that each message is passed to before the marker is inserted, for the
+// RootHighlightFormatter produced when this is passed to NewRootHighlightFormatter. Defaults to NilFormatter.
+func RootHighlightInnerFormatter(inner TraceFormatter) func(*RootHighlightFormatter) error {
+	return func(formatter *RootHighlightFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to RootHighlightFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// HyperlinkURL sets the URL template that the HyperlinkFormatter produced when this is passed to
+// NewHyperlinkFormatter will produce the target of each hyperlink from. "{file}" and "{line}" within the template are
+// replaced with the file and line number of each reference, and the template must contain "{file}". Defaults to
+// "file://{file}".
+func HyperlinkURL(template string) func(*HyperlinkFormatter) error {
+	return func(formatter *HyperlinkFormatter) error {
+		if !strings.Contains(template, "{file}") {
+			return errors.New("URL template provided to HyperlinkFormatter must contain {file}")
+		}
+
+		formatter.urlTemplate = template
+
+		return nil
+	}
+}
+
+// HyperlinkInnerFormatter sets the formatter that each message is passed to before its references are linked, for the
+// HyperlinkFormatter produced when this is passed to NewHyperlinkFormatter. Defaults to NilFormatter.
+func HyperlinkInnerFormatter(inner TraceFormatter) func(*HyperlinkFormatter) error {
+	return func(formatter *HyperlinkFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to HyperlinkFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// MessageStyle sets the ANSI escape code (e.g. "\x1b[31m" for red) that the DetailColorFormatter produced when this is
+// passed to NewDetailColorFormatter will style the message of each error with. If empty, messages are not styled.
+// Defaults to "\x1b[1m" (bold).
+func MessageStyle(style string) func(*DetailColorFormatter) error {
+	return func(formatter *DetailColorFormatter) error {
+		formatter.messageStyle = style
+
+		return nil
+	}
+}
+
+// FrameStyle sets the ANSI escape code that the DetailColorFormatter produced when this is passed to
+// NewDetailColorFormatter will style the detailed output of each error with. If empty, detailed output is not styled.
+// Defaults to "\x1b[2m" (faint).
+func FrameStyle(style string) func(*DetailColorFormatter) error {
+	return func(formatter *DetailColorFormatter) error {
+		formatter.frameStyle = style
+
+		return nil
+	}
+}
+
+// DetailColorEnabled will set whether or not the DetailColorFormatter produced when this is passed to
+// NewDetailColorFormatter applies any styles, which allows color to be turned off (e.g. when not writing to a
+// terminal) without changing the formatter. Defaults to true.
+func DetailColorEnabled(enabled bool) func(*DetailColorFormatter) error {
+	return func(formatter *DetailColorFormatter) error {
+		formatter.enabled = enabled
+
+		return nil
+	}
+}
+
+// DetailColorInnerFormatter sets the formatter that each message is passed to before it is styled, for the
+// DetailColorFormatter produced when this is passed to NewDetailColorFormatter. Defaults to NilFormatter.
+func DetailColorInnerFormatter(inner TraceFormatter) func(*DetailColorFormatter) error {
+	return func(formatter *DetailColorFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to DetailColorFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// EscapeNewlines will set the escapeNewlines flag when passed to NewControlEscapeFormatter. This flag, if set, will
+// instruct the formatter to escape the newlines within each message as "\n", along with the other non-printable
+// characters, so that each message is kept to a single line. Defaults to false.
+func EscapeNewlines(escape bool) func(*ControlEscapeFormatter) error {
+	return func(formatter *ControlEscapeFormatter) error {
+		formatter.escapeNewlines = escape
+
+		return nil
+	}
+}
+
+// ControlEscapeInnerFormatter sets the formatter that each message is passed to after it is escaped, for the
+// ControlEscapeFormatter produced when this is passed to NewControlEscapeFormatter. Defaults to NilFormatter.
+func ControlEscapeInnerFormatter(inner TraceFormatter) func(*ControlEscapeFormatter) error {
+	return func(formatter *ControlEscapeFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to ControlEscapeFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// SummaryInnerFormatter sets the formatter that each message is passed to before the summary is inserted, for the
+// SummaryFormatter produced when this is passed to NewSummaryFormatter. Defaults to NilFormatter.
+func SummaryInnerFormatter(inner TraceFormatter) func(*SummaryFormatter) error {
+	return func(formatter *SummaryFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to SummaryFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// CodeInnerFormatter sets the formatter that each message is passed to before its code is inserted, for the
+// CodeFormatter produced when this is passed to NewCodeFormatter. Defaults to NilFormatter.
+func CodeInnerFormatter(inner TraceFormatter) func(*CodeFormatter) error {
+	return func(formatter *CodeFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to CodeFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// WhenLayout sets the layout, as given to time.Time.Format, that the times shown by the WhenFormatter produced when
+// this is passed to NewWhenFormatter are written in. Defaults to time.RFC3339Nano.
+func WhenLayout(layout string) func(*WhenFormatter) error {
+	return func(formatter *WhenFormatter) error {
+		if layout == "" {
+			return errors.New("empty layout provided to WhenFormatter")
+		}
+
+		formatter.layout = layout
+
+		return nil
+	}
+}
+
+// WhenInnerFormatter sets the formatter that each message is passed to before it is annotated, for the WhenFormatter
+// produced when this is passed to NewWhenFormatter. Defaults to NilFormatter.
+func WhenInnerFormatter(inner TraceFormatter) func(*WhenFormatter) error {
+	return func(formatter *WhenFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to WhenFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
+
+// StructuredDataID sets the SD-ID of the elements produced by the RFC5424Formatter produced when this is passed to
+// NewRFC5424Formatter. As required by RFC 5424, the SD-ID must be between 1 and 32 printable US-ASCII characters, none
+// of which may be '=', ' ', ']', or '"'. Defaults to "xtrace".
+func StructuredDataID(id string) func(*RFC5424Formatter) error {
+	return func(formatter *RFC5424Formatter) error {
+		if len(id) == 0 || len(id) > 32 {
+			return errors.New("SD-ID provided to RFC5424Formatter must be between 1 and 32 characters")
+		}
+
+		for _, char := range id {
+			if char < '!' || char > '~' || strings.ContainsRune(`= ]"`, char) {
+				return errors.New("SD-ID provided to RFC5424Formatter contains an invalid character")
+			}
+		}
+
+		formatter.structuredDataID = id
+
+		return nil
+	}
+}
+
+// Connectives sets the phrases that the NarrativeFormatter produced when this is passed to NewNarrativeFormatter will
+// label the root cause, and each error that wraps another, with, in place of the Because and WhichCaused fields of the
+// Tracer's Messages. Neither phrase may be empty. Defaults to the phrases of the Tracer's Messages.
+func Connectives(because string, whichCaused string) func(*NarrativeFormatter) error {
+	return func(formatter *NarrativeFormatter) error {
+		if because == "" || whichCaused == "" {
+			return errors.New("empty phrase provided to NarrativeFormatter")
+		}
+
+		formatter.because = because
+		formatter.whichCaused = whichCaused
+
+		return nil
+	}
+}
+
+// NarrativeInnerFormatter sets the formatter that each message is passed to before it is labeled, for the
+// NarrativeFormatter produced when this is passed to NewNarrativeFormatter. Defaults to NilFormatter.
+func NarrativeInnerFormatter(inner TraceFormatter) func(*NarrativeFormatter) error {
+	return func(formatter *NarrativeFormatter) error {
+		if inner == nil {
+			return errors.New("nil formatter provided to NarrativeFormatter")
+		}
+
+		formatter.formatter = inner
+
+		return nil
+	}
+}
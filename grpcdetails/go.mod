@@ -0,0 +1,16 @@
+module github.com/ollien/xtrace/grpcdetails
+
+go 1.19
+
+require (
+	github.com/ollien/xtrace v0.0.0-20261016015139-a6a1a1acda14
+	github.com/stretchr/testify v1.3.0
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
+)
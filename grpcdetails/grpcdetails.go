@@ -0,0 +1,55 @@
+// Package grpcdetails converts traces from package xtrace into the error details of google.rpc, so that they may be
+// attached to a gRPC status. It is a separate module so that xtrace itself does not depend on gRPC.
+package grpcdetails
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"io"
+
+	"github.com/ollien/xtrace"
+	"golang.org/x/xerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// DebugInfo traces the given error with a Tracer constructed with the given options, and returns the result as a
+// DebugInfo. StackEntries holds the formatted message of each error, in the Tracer's ordering, and Detail holds the
+// formatted root cause. If the error is nil, the DebugInfo will be empty.
+func DebugInfo(err error, options ...func(*xtrace.Tracer) error) (*errdetails.DebugInfo, error) {
+	tracer, constructErr := xtrace.NewTracer(err, options...)
+	if constructErr != nil {
+		return nil, xerrors.Errorf("failed to initialize trace: %w", constructErr)
+	}
+
+	info := &errdetails.DebugInfo{StackEntries: []string{}}
+	// Root formats with a clone of the formatter, so it does not disturb the trace below.
+	root, rootErr := tracer.Root()
+	if rootErr != nil && rootErr != io.EOF {
+		return nil, xerrors.Errorf("could not find root cause: %w", rootErr)
+	} else if rootErr == nil {
+		info.Detail = root
+	}
+
+	traceErr := tracer.TraceFunc(func(index int, message string) {
+		info.StackEntries = append(info.StackEntries, message)
+	})
+	if traceErr != nil {
+		return nil, xerrors.Errorf("could not trace error: %w", traceErr)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,80 @@
+package grpcdetails
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ollien/xtrace"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDebugInfo(t *testing.T) {
+	tests := []struct {
+		name                 string
+		err                  error
+		options              []func(*xtrace.Tracer) error
+		expectedStackEntries []string
+		expectedDetail       string
+	}{
+		{
+			name:                 "wrapped errors",
+			err:                  xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")),
+			options:              []func(*xtrace.Tracer) error{xtrace.DetailedOutput(false)},
+			expectedStackEntries: []string{"things broke :(", "aw shucks"},
+			expectedDetail:       "things broke :(",
+		},
+		{
+			name: "newest first ordering",
+			err:  xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")),
+			options: []func(*xtrace.Tracer) error{
+				xtrace.DetailedOutput(false),
+				xtrace.Ordering(xtrace.NewestFirstOrdering),
+			},
+			expectedStackEntries: []string{"aw shucks", "things broke :("},
+			expectedDetail:       "things broke :(",
+		},
+		{
+			name: "stateful formatter",
+			err:  xerrors.Errorf("aw shucks: %w", errors.New("things broke")),
+			options: []func(*xtrace.Tracer) error{
+				xtrace.DetailedOutput(false),
+				xtrace.Formatter(xtrace.NewMarkdownOrderedFormatter()),
+			},
+			expectedStackEntries: []string{"1. things broke", "2. aw shucks"},
+			expectedDetail:       "1. things broke",
+		},
+		{
+			name:                 "nil error",
+			err:                  nil,
+			options:              nil,
+			expectedStackEntries: []string{},
+			expectedDetail:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := DebugInfo(tt.err, tt.options...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedStackEntries, info.GetStackEntries())
+			assert.Equal(t, tt.expectedDetail, info.GetDetail())
+		})
+	}
+}
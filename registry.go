@@ -0,0 +1,71 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// formatterRegistry holds the formatter factories registered with RegisterFormatter, keyed by name.
+var formatterRegistry = struct {
+	mux       sync.RWMutex
+	factories map[string]func() (TraceFormatter, error)
+}{
+	factories: map[string]func() (TraceFormatter, error){
+		"newline": func() (TraceFormatter, error) {
+			return NewNewLineFormatter()
+		},
+		"nested": func() (TraceFormatter, error) {
+			return NewNestedMessageFormatter()
+		},
+		"nil": func() (TraceFormatter, error) {
+			return NewNilFormatter(), nil
+		},
+	},
+}
+
+// RegisterFormatter registers the given factory under the given name, so that it may later be retrieved with
+// FormatterByName, allowing a formatter to be selected by name (e.g. from a configuration file) without importing its
+// type. Registering a name that is already registered replaces the previous factory. The built-in formatters are
+// registered as "newline" (NewLineFormatter), "nested" (NestedMessageFormatter), and "nil" (NilFormatter), each with
+// their default options.
+//
+// The registry is safe for concurrent use, so formatters may be registered and retrieved from any goroutine, though
+// it is typically most convenient to register them from an init function.
+func RegisterFormatter(name string, factory func() (TraceFormatter, error)) {
+	formatterRegistry.mux.Lock()
+	defer formatterRegistry.mux.Unlock()
+
+	formatterRegistry.factories[name] = factory
+}
+
+// FormatterByName produces an option that sets the formatter of a Tracer to one produced by the factory registered
+// under the given name with RegisterFormatter. As with FormatterFunc, the factory is called again for each copy of the
+// Tracer, so stateful formatters are safe to use. An error is returned if no factory is registered under the name.
+func FormatterByName(name string) (func(*Tracer) error, error) {
+	formatterRegistry.mux.RLock()
+	defer formatterRegistry.mux.RUnlock()
+
+	factory, isRegistered := formatterRegistry.factories[name]
+	if !isRegistered {
+		return nil, xerrors.Errorf("no formatter registered with name %q", name)
+	}
+
+	return FormatterFunc(factory), nil
+}
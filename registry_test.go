@@ -0,0 +1,97 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+// upperFormatter is a formatter that outputs each message in uppercase, for testing the formatter registry.
+type upperFormatter struct{}
+
+func (formatter upperFormatter) FormatTrace(previousMessages []string, message string) string {
+	return strings.ToUpper(message)
+}
+
+func TestFormatterByName(t *testing.T) {
+	RegisterFormatter("upper", func() (TraceFormatter, error) {
+		return upperFormatter{}, nil
+	})
+
+	option, err := FormatterByName("upper")
+	assert.Nil(t, err)
+
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke"))
+	tracer, err := NewTracer(baseErr, DetailedOutput(false), option)
+	assert.Nil(t, err)
+	assert.Equal(t, "THINGS BROKE\nAW SHUCKS", fmt.Sprintf("%v", tracer))
+}
+
+func TestFormatterByName_BuiltIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected TraceFormatter
+	}{
+		{
+			name:     "newline",
+			expected: &NewLineFormatter{},
+		},
+		{
+			name:     "nested",
+			expected: &NestedMessageFormatter{},
+		},
+		{
+			name:     "nil",
+			expected: &NilFormatter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option, err := FormatterByName(tt.name)
+			assert.Nil(t, err)
+
+			tracer, err := NewTracer(errors.New("things broke"), option)
+			assert.Nil(t, err)
+			assert.IsType(t, tt.expected, tracer.formatter)
+		})
+	}
+}
+
+func TestFormatterByName_FailingFactory(t *testing.T) {
+	RegisterFormatter("broken", func() (TraceFormatter, error) {
+		return nil, errors.New("can't make a formatter")
+	})
+
+	option, err := FormatterByName("broken")
+	assert.Nil(t, err)
+
+	_, err = NewTracer(errors.New("things broke"), option)
+	assert.NotNil(t, err)
+}
+
+func TestFormatterByName_Unregistered(t *testing.T) {
+	option, err := FormatterByName("not a formatter")
+	assert.NotNil(t, err)
+	assert.Nil(t, option)
+}
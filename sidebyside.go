@@ -0,0 +1,100 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// sideBySideSeparator is written between the two columns produced by SideBySide.
+const sideBySideSeparator = " | "
+
+// SideBySide produces the full traces of the two given Tracers, as written by Trace, in two columns, such as for
+// comparing the errors of a failing run against those of a passing one. Each line of a's trace is padded with spaces to
+// width characters, and joined with the corresponding line of b's trace by " | ". Lines that are longer than width are
+// not cut, and so push the separator on their line out of alignment.
+//
+// Lines are paired by their position from the start of each trace, regardless of which errors they belong to. If one
+// trace has fewer lines than the other, its column is left blank for the remaining lines, so chains of different
+// lengths line up at the error that is written first, which is the root cause with OldestFirstOrdering, and the
+// outermost error with NewestFirstOrdering. Neither Tracer is read from.
+func SideBySide(a, b *Tracer, width int) (string, error) {
+	if width <= 0 {
+		return "", errors.New("column width must be positive")
+	}
+
+	aTrace, err := a.TraceStringBuilder()
+	if err != nil {
+		return "", xerrors.Errorf("could not trace left column: %w", err)
+	}
+
+	bTrace, err := b.TraceStringBuilder()
+	if err != nil {
+		return "", xerrors.Errorf("could not trace right column: %w", err)
+	}
+
+	aLines := traceLines(aTrace)
+	bLines := traceLines(bTrace)
+	numLines := len(aLines)
+	if len(bLines) > numLines {
+		numLines = len(bLines)
+	}
+
+	builder := strings.Builder{}
+	for i := 0; i < numLines; i++ {
+		aLine, bLine := "", ""
+		if i < len(aLines) {
+			aLine = aLines[i]
+		}
+
+		if i < len(bLines) {
+			bLine = bLines[i]
+		}
+
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		padding := width - utf8.RuneCountInString(aLine)
+		if padding < 0 {
+			padding = 0
+		}
+
+		row := aLine + strings.Repeat(" ", padding) + sideBySideSeparator + bLine
+		if bLine == "" {
+			// There is nothing after the separator, so there is no sense in leaving its padding behind
+			row = strings.TrimRight(row, " ")
+		}
+
+		builder.WriteString(row)
+	}
+
+	return builder.String(), nil
+}
+
+// traceLines splits the given trace into its lines, with an empty trace having no lines.
+func traceLines(trace string) []string {
+	if trace == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(trace, "\n"), "\n")
+}
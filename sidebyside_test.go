@@ -0,0 +1,87 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestSideBySide(t *testing.T) {
+	rootErr := errors.New("things broke :(")
+	shortErr := xerrors.Errorf("aw shucks: %w", rootErr)
+	longErr := xerrors.Errorf("I tried very hard and failed: %w", xerrors.Errorf("retrying: %w", shortErr))
+
+	tests := []struct {
+		name     string
+		a        error
+		b        error
+		width    int
+		expected string
+	}{
+		{
+			name:  "longer chain on the right",
+			a:     shortErr,
+			b:     longErr,
+			width: 16,
+			expected: "things broke :(  | things broke :(\n" +
+				"aw shucks        | aw shucks\n" +
+				"                 | retrying\n" +
+				"                 | I tried very hard and failed",
+		},
+		{
+			name:  "longer chain on the left",
+			a:     longErr,
+			b:     shortErr,
+			width: 16,
+			expected: "things broke :(  | things broke :(\n" +
+				"aw shucks        | aw shucks\n" +
+				"retrying         |\n" +
+				"I tried very hard and failed |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewTracer(tt.a, DetailedOutput(false))
+			assert.Nil(t, err)
+
+			b, err := NewTracer(tt.b, DetailedOutput(false))
+			assert.Nil(t, err)
+
+			output, err := SideBySide(a, b, tt.width)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			// Neither Tracer should have been read from
+			message, err := a.ReadNext()
+			assert.Nil(t, err)
+			assert.Equal(t, "things broke :(", message)
+		})
+	}
+}
+
+func TestSideBySide_InvalidWidth(t *testing.T) {
+	a, err := NewTracer(errors.New("things broke :("))
+	assert.Nil(t, err)
+
+	_, err = SideBySide(a, a, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,155 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DedupeSink is an io.Writer that suppresses repeated traces of errors that share a root cause, such as those from a
+// retry loop, which would otherwise flood the output. See NewDedupeSink for more info.
+type DedupeSink struct {
+	writer io.Writer
+	window time.Duration
+	// Produces the current time, which may be replaced in tests
+	now func() time.Time
+	// The state of each root cause that has been written, keyed by the hash of the root cause
+	seen map[string]*dedupeEntry
+	mux  sync.Mutex
+}
+
+// dedupeEntry holds the state of the traces written to a DedupeSink for a single root cause.
+type dedupeEntry struct {
+	// When the last trace that was not suppressed was written
+	windowStart time.Time
+	// The number of traces that have been suppressed since then
+	suppressed int
+}
+
+// NewDedupeSink wraps the given io.Writer so that repeated traces of errors that share a root cause are suppressed.
+// Each trace written by a Tracer is deduplicated as a whole, whether it is written with WriteTrace, or by passing the
+// DedupeSink directly to Tracer.Trace or any of the package-level functions that take an io.Writer, such as
+// TraceRecover. All other writes to the DedupeSink are passed through as is, as the sink cannot know which trace they
+// belong to, if any.
+//
+// Each trace is identified by the hash of its root cause, which is the same as that shown by the ShowHash option: the
+// FNV-1a hash of the root cause's message, without any detailed output. For a Tracer constructed with NewMultiTracer,
+// the hashes of the root causes of all of its top-level errors are combined. The first trace of a root cause is
+// written as is, and starts a window of the given duration, within which all other traces of the same root cause are
+// suppressed. The first trace of the root cause after the window has passed is written, preceded by a line that counts
+// the traces suppressed within the window (e.g. "(suppressed 3x)"), and starts a new window. So that the sink does not
+// grow without bound, every root cause whose window has passed is forgotten each time a trace is written, and the count
+// for its last window along with it. As such, the count for a window is only written if the root cause is traced again
+// before any other trace is written once the window has passed. The returned DedupeSink is safe for concurrent use.
+func NewDedupeSink(writer io.Writer, window time.Duration) *DedupeSink {
+	return &DedupeSink{
+		writer: writer,
+		window: window,
+		now:    time.Now,
+		seen:   map[string]*dedupeEntry{},
+	}
+}
+
+// Write implements io.Writer. Writes made by a Tracer are deduplicated per trace, as described by NewDedupeSink, as the
+// Tracer hands its full trace to the sink at once; all other data is passed through as is.
+func (sink *DedupeSink) Write(data []byte) (int, error) {
+	sink.mux.Lock()
+	defer sink.mux.Unlock()
+
+	return sink.writer.Write(data)
+}
+
+// WriteTrace writes the full trace of the given Tracer, followed by a newline, as the package-level functions do,
+// unless it is suppressed. As with Tracer.Trace, no errors are consumed from the Tracer.
+func (sink *DedupeSink) WriteTrace(tracer *Tracer) error {
+	clone, err := tracer.clone(tracer.baseErrs...)
+	if err != nil {
+		return xerrors.Errorf("failed to recreate Tracer for re-tracing: %w", err)
+	}
+
+	return sink.traceFrom(clone, "\n")
+}
+
+// traceFrom writes the full trace of the given Tracer, followed by the given terminator, unless it is suppressed. The
+// trace is produced up front, so that it is always written or suppressed as a whole. Unlike WriteTrace, the errors of
+// the Tracer are consumed.
+func (sink *DedupeSink) traceFrom(tracer *Tracer, terminator string) error {
+	rootHashes := []string{}
+	for _, baseErr := range tracer.baseErrs {
+		chain := tracer.chainOf(baseErr)
+		if len(chain) > 0 {
+			rootHashes = append(rootHashes, hashError(chain[len(chain)-1]))
+		}
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err := tracer.trace(buffer)
+	if err != nil {
+		return xerrors.Errorf("failed to run trace: %w", err)
+	}
+
+	buffer.WriteString(terminator)
+	err = sink.writeTrace(strings.Join(rootHashes, ","), buffer.Bytes())
+	if err != nil {
+		return xerrors.Errorf("failed to write trace to sink: %w", err)
+	}
+
+	return nil
+}
+
+// writeTrace writes the given trace, whose root causes have the given key, unless it is suppressed.
+func (sink *DedupeSink) writeTrace(key string, trace []byte) error {
+	sink.mux.Lock()
+	defer sink.mux.Unlock()
+
+	now := sink.now()
+	entry, isSeen := sink.seen[key]
+	if isSeen && now.Sub(entry.windowStart) < sink.window {
+		entry.suppressed++
+
+		return nil
+	}
+
+	if isSeen && entry.suppressed > 0 {
+		_, err := fmt.Fprintf(sink.writer, "(suppressed %dx)\n", entry.suppressed)
+		if err != nil {
+			return err
+		}
+	}
+
+	sink.evictExpired(now)
+	sink.seen[key] = &dedupeEntry{windowStart: now}
+	_, err := sink.writer.Write(trace)
+
+	return err
+}
+
+// evictExpired forgets every root cause whose window has passed by the given time.
+func (sink *DedupeSink) evictExpired(now time.Time) {
+	for key, entry := range sink.seen {
+		if now.Sub(entry.windowStart) >= sink.window {
+			delete(sink.seen, key)
+		}
+	}
+}
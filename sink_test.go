@@ -0,0 +1,164 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDedupeSink(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time {
+		return now
+	}
+
+	rootErr := errors.New("connection refused")
+	traceErr := func(err error) {
+		tracer, constructErr := NewTracer(err, DetailedOutput(false))
+		assert.Nil(t, constructErr)
+		assert.Nil(t, sink.WriteTrace(tracer))
+	}
+
+	traceErr(xerrors.Errorf("attempt 1 failed: %w", rootErr))
+	now = now.Add(10 * time.Second)
+	traceErr(xerrors.Errorf("attempt 2 failed: %w", rootErr))
+	traceErr(xerrors.Errorf("attempt 3 failed: %w", rootErr))
+	traceErr(errors.New("something else broke"))
+	now = now.Add(time.Minute)
+	traceErr(xerrors.Errorf("attempt 4 failed: %w", rootErr))
+
+	expected := "connection refused\nattempt 1 failed\n" +
+		"something else broke\n" +
+		"(suppressed 2x)\nconnection refused\nattempt 4 failed\n"
+	assert.Equal(t, expected, output.String())
+}
+
+func TestDedupeSink_MultiTracer(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	errs := []error{errors.New("things broke"), errors.New("oh no")}
+	tracer, err := NewMultiTracer(errs, DetailedOutput(false))
+	assert.Nil(t, err)
+	assert.Nil(t, sink.WriteTrace(tracer))
+	assert.Nil(t, sink.WriteTrace(tracer))
+
+	// Only one of the root causes is shared, so this is not a repeat.
+	otherTracer, err := NewMultiTracer(errs[:1], DetailedOutput(false))
+	assert.Nil(t, err)
+	assert.Nil(t, sink.WriteTrace(otherTracer))
+
+	assert.Equal(t, "things broke\noh no\nthings broke\n", output.String())
+}
+
+func TestDedupeSink_TracerOptions(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	// Options that wrap the writer of a trace must still apply to a deduplicated trace.
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, constructErr := NewTracer(err, DetailedOutput(false), LinePrefix(func() string { return "> " }))
+	assert.Nil(t, constructErr)
+	assert.Nil(t, sink.WriteTrace(tracer))
+	assert.Nil(t, sink.WriteTrace(tracer))
+
+	assert.Equal(t, "> things broke :(\n> aw shucks\n", output.String())
+}
+
+func TestDedupeSink_Trace(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	// Traces written by passing the sink to a Tracer must be deduplicated, just as those written with WriteTrace are.
+	rootErr := errors.New("connection refused")
+	for i := 1; i <= 3; i++ {
+		tracer, err := NewTracer(xerrors.Errorf("attempt %d failed: %w", i, rootErr), DetailedOutput(false))
+		assert.Nil(t, err)
+		assert.Nil(t, tracer.Trace(sink))
+	}
+
+	assert.Equal(t, "connection refused\nattempt 1 failed", output.String())
+}
+
+func TestDedupeSink_TraceRecover(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	// The terminating newline must be suppressed along with the trace.
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, TraceRecover(errors.New("things broke :("), sink, DetailedOutput(false)))
+	}
+
+	assert.Equal(t, "things broke :(\n", output.String())
+}
+
+func TestDedupeSink_Eviction(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time {
+		return now
+	}
+
+	for _, message := range []string{"things broke :(", "oh no", "aw shucks"} {
+		tracer, err := NewTracer(errors.New(message), DetailedOutput(false))
+		assert.Nil(t, err)
+		assert.Nil(t, sink.WriteTrace(tracer))
+		now = now.Add(40 * time.Second)
+	}
+
+	// Each time a trace is written, only the root causes whose windows have passed are forgotten.
+	assert.Equal(t, 2, len(sink.seen))
+	assert.Nil(t, sink.writeTrace("unrelated", []byte{}))
+	assert.Equal(t, 2, len(sink.seen))
+}
+
+func TestDedupeSink_PlainWrites(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	_, err := sink.Write([]byte("hello\n"))
+	assert.Nil(t, err)
+	_, err = sink.Write([]byte("hello\n"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "hello\nhello\n", output.String())
+}
+
+func TestDedupeSink_PlainWritesAfterSuppressedTrace(t *testing.T) {
+	output := bytes.NewBufferString("")
+	sink := NewDedupeSink(output, time.Minute)
+
+	tracer, err := NewTracer(errors.New("things broke :("), DetailedOutput(false))
+	assert.Nil(t, err)
+	assert.Nil(t, sink.WriteTrace(tracer))
+	assert.Nil(t, sink.WriteTrace(tracer))
+
+	// Suppression only applies to the trace itself, so an unrelated write must not be dropped.
+	_, err = sink.Write([]byte("hello\n"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "things broke :(\nhello\n", output.String())
+}
@@ -0,0 +1,98 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sourceIndentation is the indentation of the source, if the reference it belongs to is not indented itself, matching
+// the indentation that xerrors uses for file and line number references.
+const sourceIndentation = "    "
+
+// referencedSource produces the lines of source surrounding each file and line number reference in the message, with
+// the given number of lines of context on either side. References to files that can not be read, or to lines that are
+// not in the file, are skipped.
+func referencedSource(message string, contextLines int) []string {
+	source := []string{}
+	for _, line := range strings.Split(message, "\n") {
+		submatches := fileLinePattern.FindStringSubmatch(line)
+		if submatches == nil {
+			continue
+		}
+
+		lineNumber, err := strconv.Atoi(submatches[2])
+		if err != nil {
+			continue
+		}
+
+		// xerrors writes the indentation of a reference along with the function before it, so it is not always found on
+		// the same line as the reference.
+		indentation := line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		if indentation == "" {
+			indentation = sourceIndentation
+		}
+
+		source = append(source, sourceSnippet(submatches[1], lineNumber, contextLines, indentation)...)
+	}
+
+	return source
+}
+
+// sourceSnippet reads the lines of the given file surrounding the given line number, and formats them with their line
+// numbers, marking the given line with ">" in place of the "|" that follows the other line numbers. Each line is
+// prefixed with the given indentation. If the file can not be read, or the line number is not in the file, nil is
+// returned.
+func sourceSnippet(file string, lineNumber int, contextLines int, indentation string) []string {
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	fileLines := strings.Split(strings.TrimRight(string(contents), "\n"), "\n")
+	if lineNumber < 1 || lineNumber > len(fileLines) {
+		return nil
+	}
+
+	start := lineNumber - contextLines
+	if start < 1 {
+		start = 1
+	}
+
+	end := lineNumber + contextLines
+	if end > len(fileLines) {
+		end = len(fileLines)
+	}
+
+	numberWidth := len(strconv.Itoa(end))
+	snippet := make([]string, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		separator := "|"
+		if i == lineNumber {
+			separator = ">"
+		}
+
+		sourceLine := strings.TrimRight(fileLines[i-1], " \t\r")
+		snippet = append(snippet, fmt.Sprintf("%s%*d %s %s", indentation, numberWidth, i, separator, sourceLine))
+	}
+
+	return snippet
+}
@@ -0,0 +1,111 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+// sourceTestError is an error whose detailed output refers to the given file and line.
+type sourceTestError struct {
+	file string
+	line int
+}
+
+func (err sourceTestError) Error() string {
+	return "things broke :("
+}
+
+func (err sourceTestError) FormatError(printer xerrors.Printer) error {
+	printer.Print("things broke :(")
+	if printer.Detail() {
+		printer.Print("main.main\n    ")
+		printer.Printf("%s:%d\n", err.file, err.line)
+	}
+
+	return nil
+}
+
+func TestShowSource(t *testing.T) {
+	fixturePath, err := filepath.Abs(filepath.Join("testdata", "source_fixture.txt"))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		err      error
+		options  []func(*Tracer) error
+		expected string
+	}{
+		{
+			name:    "known fixture",
+			err:     sourceTestError{file: fixturePath, line: 5},
+			options: []func(*Tracer) error{ShowSource(1)},
+			expected: "things broke :(main.main\n    " + fixturePath + ":5\n" +
+				"    4 | \tdoThings()\n" +
+				"    5 > \tpanic(\"things broke :(\")\n" +
+				"    6 | }\n",
+		},
+		{
+			name:    "start of file",
+			err:     sourceTestError{file: fixturePath, line: 1},
+			options: []func(*Tracer) error{ShowSource(1)},
+			expected: "things broke :(main.main\n    " + fixturePath + ":1\n" +
+				"    1 > package main\n" +
+				"    2 | \n",
+		},
+		{
+			name:     "missing file",
+			err:      sourceTestError{file: filepath.Join("testdata", "missing.go"), line: 5},
+			options:  []func(*Tracer) error{ShowSource(1)},
+			expected: "things broke :(main.main\n    " + filepath.Join("testdata", "missing.go") + ":5\n",
+		},
+		{
+			name:     "line out of range",
+			err:      sourceTestError{file: fixturePath, line: 50},
+			options:  []func(*Tracer) error{ShowSource(1)},
+			expected: "things broke :(main.main\n    " + fixturePath + ":50\n",
+		},
+		{
+			name:     "no detailed output",
+			err:      sourceTestError{file: fixturePath, line: 5},
+			options:  []func(*Tracer) error{ShowSource(1), DetailedOutput(false)},
+			expected: "things broke :(",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]func(*Tracer) error{Formatter(NewNilFormatter())}, tt.options...)
+			tracer, err := NewTracer(tt.err, options...)
+			assert.Nil(t, err)
+
+			message, err := tracer.ReadNext()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, message, fmt.Sprintf("%q", message))
+		})
+	}
+}
+
+func TestShowSource_Negative(t *testing.T) {
+	_, err := NewTracer(sourceTestError{}, ShowSource(-1))
+	assert.NotNil(t, err)
+}
@@ -17,6 +17,7 @@ package xtrace
 */
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -24,25 +25,68 @@ import (
 )
 
 // Trace prints a trace of errors wrapped by xerrors to stderr, with a terminating newline. If more customization is
-// desired, please use Tracer.
+// desired, please use TraceWith or Tracer.
 func Trace(baseErr error) error {
 	return traceToWriter(baseErr, os.Stderr)
 }
 
+// TraceWith is identical to Trace, but uses a Tracer constructed with the given options. If baseErr is nil, what is
+// written is controlled by the NilRendering option.
+func TraceWith(baseErr error, options ...func(*Tracer) error) error {
+	return traceToWriter(baseErr, os.Stderr, options...)
+}
+
+// TraceRecover writes a trace of the given value, as returned by recover(), to the given io.Writer, with a terminating
+// newline, using a Tracer constructed with the given options, as with TraceWith. If the recovered value is not an
+// error, it is formatted with fmt and written on its own line, noting that the panic was not caused by an error (e.g.
+// "panic with non-error value: something broke"). If the recovered value is nil (i.e. there was no panic), nothing is
+// written.
+func TraceRecover(recovered interface{}, writer io.Writer, options ...func(*Tracer) error) error {
+	if recovered == nil {
+		return nil
+	}
+
+	recoveredErr, isError := recovered.(error)
+	if !isError {
+		_, err := fmt.Fprintf(writer, "panic with non-error value: %v\n", recovered)
+		if err != nil {
+			return xerrors.Errorf("could not write recovered value: %w", err)
+		}
+
+		return nil
+	}
+
+	return traceToWriter(recoveredErr, writer, options...)
+}
+
 // traceToWriter creates a Tracer and calls trace on it.
-func traceToWriter(baseErr error, writer io.Writer) error {
-	tracer, err := NewTracer(baseErr)
+func traceToWriter(baseErr error, writer io.Writer, options ...func(*Tracer) error) error {
+	tracer, err := NewTracer(baseErr, options...)
 	if err != nil {
 		return xerrors.Errorf("failed to initialize trace: %w", err)
 	}
 
+	if baseErr == nil {
+		_, err = io.WriteString(writer, tracer.nilRendering.output)
+		if err != nil {
+			return xerrors.Errorf("could not write trace of nil error: %w", err)
+		}
+
+		return nil
+	}
+
+	// The terminating newline must be suppressed along with the trace, if it is.
+	if sink, isSink := writer.(*DedupeSink); isSink {
+		return sink.traceFrom(tracer, tracer.lineEnding())
+	}
+
 	err = tracer.trace(writer)
 	if err != nil {
 		return xerrors.Errorf("failed to run trace: %w", err)
 	}
 
 	// The default tracer does not end with a newline, so write one.
-	_, err = writer.Write([]byte("\n"))
+	_, err = io.WriteString(writer, tracer.lineEnding())
 	if err != nil {
 		return xerrors.Errorf("could not complete trace to stderr: %w", err)
 	}
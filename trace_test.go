@@ -71,6 +71,90 @@ func TestTrace(t *testing.T) {
 				}())
 			},
 		},
+		{
+			name: "nil error, default",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				traceErr := traceToWriter(nil, buffer)
+				assert.Nil(t, traceErr)
+				assert.Equal(t, "\n", buffer.String())
+			},
+		},
+		{
+			name: "nil error, empty",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				traceErr := traceToWriter(nil, buffer, NilRendering(Empty))
+				assert.Nil(t, traceErr)
+				assert.Equal(t, "", buffer.String())
+			},
+		},
+		{
+			name: "nil error, newline",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				traceErr := traceToWriter(nil, buffer, NilRendering(Newline))
+				assert.Nil(t, traceErr)
+				assert.Equal(t, "\n", buffer.String())
+			},
+		},
+		{
+			name: "nil error, placeholder",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				traceErr := traceToWriter(nil, buffer, NilRendering(Placeholder("<no error>")))
+				assert.Nil(t, traceErr)
+				assert.Equal(t, "<no error>\n", buffer.String())
+			},
+		},
+	}
+
+	runTraceTestTable(t, tests)
+}
+
+func TestTraceRecover(t *testing.T) {
+	tests := []traceTest{
+		{
+			name: "error",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				func() {
+					defer func() {
+						traceErr := TraceRecover(recover(), buffer, DetailedOutput(false))
+						assert.Nil(t, traceErr)
+					}()
+
+					panic(xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")))
+				}()
+
+				assert.Equal(t, "things broke :(\naw shucks\n", buffer.String())
+			},
+		},
+		{
+			name: "non-error",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				func() {
+					defer func() {
+						traceErr := TraceRecover(recover(), buffer)
+						assert.Nil(t, traceErr)
+					}()
+
+					panic("things broke :(")
+				}()
+
+				assert.Equal(t, "panic with non-error value: things broke :(\n", buffer.String())
+			},
+		},
+		{
+			name: "no panic",
+			testFunc: func(t *testing.T) {
+				buffer := bytes.NewBufferString("")
+				traceErr := TraceRecover(nil, buffer)
+				assert.Nil(t, traceErr)
+				assert.Equal(t, "", buffer.String())
+			},
+		},
 	}
 
 	runTraceTestTable(t, tests)
@@ -18,51 +18,172 @@ package xtrace
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/xerrors"
 )
 
 const emptyError = "<empty>"
 
+// byteOrderMark is the UTF-8 encoding of the byte order mark, U+FEFF.
+const byteOrderMark = "\xef\xbb\xbf"
+
 // Tracer gets the trace of errors wrapped by xerrors.
 type Tracer struct {
 	detailedOutput bool
-	// Populated with the full chain of errors, with the originating error at len(errorChain) - 1
-	errorChain []error
+	// If not zero, only the errors with a depth less than this will have detailed output
+	detailDepth int
+	// The types of errors that never have detailed output
+	suppressedDetailTypes []reflect.Type
+	// If not zero, the number of lines of source to show on either side of each frame in detailed output
+	sourceLines int
+	// Whether or not errors that do not implement xerrors.Formatter should fall back to %+v for their detailed output
+	verboseFallback bool
+	// If not zero, the width at which the lines of detailed output are wrapped
+	frameWidth int
+	// If set, produces a prefix that is written before each line of a full trace
+	linePrefix func() string
+	// Whether or not the prefix shared by all of the messages should be removed from each of them
+	stripCommonPrefix bool
+	// The prefix that is removed from each message, if stripCommonPrefix is set
+	commonPrefix string
+	// The fields attached with WithField, which are written once at the start of each full trace
+	fields map[string]interface{}
+	// What the package-level tracing functions write in place of the trace of a nil error
+	nilRendering NilRenderingMode
+	// If set, called after each call to the formatter, for debugging purposes
+	onFormat func(previous []string, message, formatted string)
+	// If set, called with the time taken to produce the output of each error, for profiling purposes
+	onErrorTimed func(index int, duration time.Duration)
+	// Populated with the chain of errors currently being read, in the order that they will be read
+	errorChain []chainLink
+	// The chains of the top-level errors that have yet to be read, when using NewMultiTracer. Each chain holds the
+	// originating error at len(chain) - 1
+	pendingChains [][]error
 	// Holds the contents of the current error being read
 	buffer *bytes.Buffer
 	// Formats the traces returned by the Read functions
 	formatter TraceFormatter
+	// If set, the factory given with FormatterFunc, which makes a fresh formatter for each Tracer made by a
+	// TracerFactory
+	formatterFactory func() (TraceFormatter, error)
+	// If set, wraps the xerrors.Printer given to each error's FormatError method
+	wrapPrinter func(xerrors.Printer) xerrors.Printer
+	// Inserted between the message of each error and its detailed output
+	detailSeparator string
+	// Whether or not to prefix each error with a hash of its message
+	showHash bool
+	// Whether or not to start each full trace with the number of errors in it
+	showCount bool
+	// Whether or not to start the output with a UTF-8 byte order mark
+	withBOM bool
+	// Whether or not the byte order mark has been read from the Tracer with Read
+	bomRead bool
 	// Sets the order of the method
 	ordering TraceOrderingMethod
+	// Whether or not the ordering was explicitly set with the Ordering, ReadOrdering, or TraceOrdering options
+	orderingSet bool
+	// If set, overrides ordering for the Read methods
+	readOrdering *TraceOrderingMethod
+	// If set, overrides ordering for full traces
+	traceOrdering *TraceOrderingMethod
+	// If set, the errors of each chain will be sorted by this key
+	orderingKey func(error) int
+	// Whether or not the top-level errors should be sorted by their messages
+	sortBranches bool
+	// Written between the traces of each top-level error when writing a full trace
+	groupSeparator string
+	// The maximum number of bytes a full trace may write, or zero if there is no maximum
+	maxBytes int
+	// The maximum number of lines a full trace may write, or zero if there is no maximum
+	maxLines int
+	// The user-facing strings written by the Tracer
+	messages Messages
+	// Whether or not full traces should write each error's message verbatim, bypassing the formatter
+	rawBlock bool
+	// Whether or not full traces should end with the newline that follows each error
+	keepTrailing bool
+	// Whether or not the chain should be built by following Cause in place of Unwrap, where present
+	preferCause bool
+	// Whether or not ReadNext should return io.EOF along with the last message
+	eofWithLastMessage bool
+	// If set, each chain ends just before the first error that matches this one
+	chainBase error
+	// If not nil, each chain only holds the errors that match one of these
+	chainTargets []error
+	// If set, each chain ends at the first error that this returns true for
+	stopAt func(error) bool
+	// Whether or not the error that stopAt returns true for is kept in the chain
+	stopAtInclusive bool
+	// The number of errors in errorChain, before any have been read
+	chainLength int
+	// The chain that errorChain was built from, which holds the originating error at len(chain) - 1
+	currentChain []error
+	// The number of errors that have been read from the chain
+	readCount int
+	// The number of top-level errors whose chains have been started
+	groupCount int
 	// baseError is the original error passed, primarily used for cloning purposes
 	baseErr error
+	// baseErrs holds all of the top-level errors passed, primarily used for cloning purposes
+	baseErrs []error
 	// holds all of the option functions passed to the tracer, primarily used for cloning purposes
 	optionFuncs []func(*Tracer) error
+	// whether or not this tracer was cloned from another Tracer, in which case it must not share resources with it
+	isClone bool
 	// ensures that only one read can take place at a time
-	readMux sync.Mutex
+	readMux sync.Locker
 }
 
 // NewTracer returns a new Tracer for the given error.
 func NewTracer(baseErr error, options ...func(*Tracer) error) (*Tracer, error) {
+	return newTracer([]error{baseErr}, options...)
+}
+
+// NewMultiTracer returns a new Tracer for the given independent errors. Each error is treated as a top-level error, and
+// its trace, including all of the errors it wraps, is outputted in full before moving on to the next top-level error.
+// The top-level errors are traced in the order they appear in errs, unless the SortBranches option is set; the Ordering
+// option only controls the order of the errors within each top-level error's chain. Any nil errors in errs are skipped.
+// To trace each of the branches of an error produced by errors.Join, pass the result of its Unwrap method as errs.
+func NewMultiTracer(errs []error, options ...func(*Tracer) error) (*Tracer, error) {
+	return newTracer(errs, options...)
+}
+
+// newTracer returns a new Tracer that traces each of the given errors in sequence.
+func newTracer(baseErrs []error, options ...func(*Tracer) error) (*Tracer, error) {
 	formatter, err := NewNewLineFormatter(Naive(false))
 	if err != nil {
 		return nil, xerrors.Errorf("Could not construct formatter for Tracer: %w")
 	}
 
 	tracer := &Tracer{
-		errorChain:     buildErrorChain(baseErr),
 		detailedOutput: true,
 		buffer:         bytes.NewBuffer([]byte{}),
 		formatter:      formatter,
+		readMux:        &sync.Mutex{},
 		ordering:       OldestFirstOrdering,
-		baseErr:        baseErr,
+		messages:       defaultMessages,
+		nilRendering:   Newline,
+		baseErrs:       baseErrs,
 		optionFuncs:    options,
 	}
 
+	if len(baseErrs) == 1 {
+		tracer.baseErr = baseErrs[0]
+	}
+
 	for _, optionFunc := range options {
 		err := optionFunc(tracer)
 		if err != nil {
@@ -70,21 +191,347 @@ func NewTracer(baseErr error, options ...func(*Tracer) error) (*Tracer, error) {
 		}
 	}
 
+	if tracer.readOrdering != nil {
+		tracer.ordering = *tracer.readOrdering
+	}
+
+	tracer.rebuildChain()
+
 	return tracer, nil
 }
 
-// buildErrChain builds a slice of all of the errors with the oldest at the back of the list.
-func buildErrorChain(baseErr error) []error {
+// rebuildChain rebuilds the error chain from the errors the Tracer was constructed with, as if no errors had been read.
+func (tracer *Tracer) rebuildChain() {
+	chains := [][]error{}
+	for _, baseErr := range tracer.baseErrs {
+		chain := tracer.chainOf(baseErr)
+		if len(chain) > 0 {
+			chains = append(chains, chain)
+		}
+	}
+
+	if tracer.sortBranches {
+		sort.SliceStable(chains, func(i, j int) bool {
+			return plainMessage(chains[i][0]) < plainMessage(chains[j][0])
+		})
+	}
+
+	if tracer.stripCommonPrefix {
+		tracer.commonPrefix = commonMessagePrefix(chains)
+	}
+
+	tracer.errorChain = nil
+	tracer.pendingChains = chains
+	tracer.chainLength = 0
+	tracer.readCount = 0
+	tracer.groupCount = 0
+	tracer.advanceChain()
+}
+
+// commonMessagePrefix finds the longest prefix that ends in ": " and is shared by the messages of all of the errors in
+// the given chains. If there are fewer than two errors, there is no common prefix, and "" is returned.
+func commonMessagePrefix(chains [][]error) string {
+	messages := []string{}
+	for _, chain := range chains {
+		for _, err := range chain {
+			messages = append(messages, plainMessage(err))
+		}
+	}
+
+	if len(messages) < 2 {
+		return ""
+	}
+
+	prefix := messages[0]
+	for _, message := range messages[1:] {
+		i := 0
+		for i < len(prefix) && i < len(message) && prefix[i] == message[i] {
+			i++
+		}
+
+		prefix = prefix[:i]
+	}
+
+	separatorIndex := strings.LastIndex(prefix, ": ")
+	if separatorIndex == -1 {
+		return ""
+	}
+
+	return prefix[:separatorIndex+len(": ")]
+}
+
+// clone makes a new Tracer for the given errors with the same options as this Tracer. Resources that can not be safely
+// shared between Tracers, such as a buffer passed with the Buffer option, are not shared with the clone.
+func (tracer *Tracer) clone(baseErrs ...error) (*Tracer, error) {
+	options := append([]func(*Tracer) error{markClone}, tracer.optionFuncs...)
+
+	return newTracer(baseErrs, options...)
+}
+
+// markClone marks the Tracer as a clone when passed to NewTracer.
+func markClone(tracer *Tracer) error {
+	tracer.isClone = true
+
+	return nil
+}
+
+// noopLocker is a sync.Locker that does nothing, used when a Tracer is unsynchronized.
+type noopLocker struct{}
+
+// Lock does nothing.
+func (locker noopLocker) Lock() {}
+
+// Unlock does nothing.
+func (locker noopLocker) Unlock() {}
+
+// chainLink is a single error within an error chain.
+type chainLink struct {
+	err error
+	// The position of the error within its chain, where the root cause has a depth of zero
+	depth int
+	// The error that wraps err, or nil if there is none
+	wrapper error
+}
+
+// buildErrChain builds a slice of all of the errors with the oldest at the back of the list. If preferCause is set,
+// each error's Cause method is followed in place of Unwrap, where present.
+func buildErrorChain(baseErr error, preferCause bool) []error {
 	chain := []error{}
 	errCursor := baseErr
 	for errCursor != nil {
 		chain = append(chain, errCursor)
-		errCursor = xerrors.Unwrap(errCursor)
+		nextErr := unwrapError(errCursor, preferCause)
+		// An error that unwraps to itself would otherwise have us loop forever.
+		if isSameError(nextErr, errCursor) {
+			break
+		}
+
+		errCursor = nextErr
 	}
 
 	return chain
 }
 
+// chainOf builds the chain of the given top-level error, as with buildErrorChain, but ending just before the Tracer's
+// chain base, if it has one, and holding only the errors that match one of the Tracer's chain targets, if it has any.
+func (tracer *Tracer) chainOf(baseErr error) []error {
+	chain := buildErrorChain(baseErr, tracer.preferCause)
+	if tracer.chainBase != nil {
+		for i, err := range chain {
+			if errorMatchesBase(err, tracer.chainBase) {
+				chain = chain[:i]
+				break
+			}
+		}
+	}
+
+	if tracer.stopAt != nil {
+		for i, err := range chain {
+			if !tracer.stopAt(err) {
+				continue
+			}
+
+			if tracer.stopAtInclusive {
+				i++
+			}
+
+			chain = chain[:i]
+			break
+		}
+	}
+
+	if tracer.chainTargets == nil {
+		return chain
+	}
+
+	matchingChain := []error{}
+	for _, err := range chain {
+		for _, target := range tracer.chainTargets {
+			if errorMatchesBase(err, target) {
+				matchingChain = append(matchingChain, err)
+				break
+			}
+		}
+	}
+
+	return matchingChain
+}
+
+// errorMatchesBase checks if the given error, without unwrapping, is the given base error, either by identity or by its
+// own Is method.
+func errorMatchesBase(err error, base error) bool {
+	if isSameError(err, base) {
+		return true
+	}
+
+	iser, isIser := err.(interface{ Is(error) bool })
+
+	return isIser && iser.Is(base)
+}
+
+// unwrapError finds the error that the given error wraps. If preferCause is set, and the error has a Cause method, its
+// result is used; otherwise, the error is unwrapped as with xerrors.Unwrap.
+func unwrapError(err error, preferCause bool) error {
+	causer, isCauser := err.(interface{ Cause() error })
+	if preferCause && isCauser {
+		return causer.Cause()
+	}
+
+	return xerrors.Unwrap(err)
+}
+
+// isSameError checks if the two errors are identical. Errors whose types are not comparable are never identical.
+func isSameError(err1 error, err2 error) bool {
+	if err1 == nil || err2 == nil || reflect.TypeOf(err1) != reflect.TypeOf(err2) {
+		return false
+	} else if !reflect.TypeOf(err1).Comparable() {
+		return false
+	}
+
+	return err1 == err2
+}
+
+// From finds the first error in the chain, starting from the most recent error, that satisfies errors.As for the given
+// target, and returns a new Tracer whose chain starts at that error and continues towards the root cause. Only the
+// error itself is checked against the target; the errors it wraps are not considered when deciding where the new chain
+// starts. The new Tracer is constructed with the same options as this one, so the ordering of the subchain follows the
+// same ordering as this Tracer (e.g. with NewestFirstOrdering, the matched error is read first, and with
+// OldestFirstOrdering, it is read last). The state of this Tracer is not modified, and From considers the full chain
+// regardless of how many errors have already been read. Returns an error if no error in the chain matches the target.
+func (tracer *Tracer) From(target interface{}) (*Tracer, error) {
+	if target == nil {
+		return nil, errors.New("target for From must be a non-nil pointer")
+	}
+
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return nil, errors.New("target for From must be a non-nil pointer")
+	}
+
+	for _, baseErr := range tracer.baseErrs {
+		for _, err := range tracer.chainOf(baseErr) {
+			if !errorMatchesTarget(err, targetValue) {
+				continue
+			}
+
+			subTracer, constructErr := tracer.clone(err)
+			if constructErr != nil {
+				return nil, xerrors.Errorf("could not construct Tracer for subchain: %w", constructErr)
+			}
+
+			return subTracer, nil
+		}
+	}
+
+	return nil, errors.New("no error in the chain matches the given target")
+}
+
+// SinceBase finds the first error in the chain, starting from the most recent error, that matches the given base error,
+// and returns a new Tracer whose chain holds only the errors that wrap it, i.e. the errors above it in the chain. This
+// isolates the context that has been added on top of the base error, such as by the latest attempt of a retry loop.
+// An error matches if it is identical to base, or if its own Is method reports that it matches base, as with
+// errors.Is; the errors it wraps are not considered, as otherwise every error above base would match. For a Tracer
+// constructed with NewMultiTracer, only the first top-level error whose chain contains base is included.
+//
+// The new Tracer is constructed with the same options as this one, so the errors above base follow the same ordering
+// as this Tracer (e.g. with NewestFirstOrdering, the most recent error is read first, and with OldestFirstOrdering,
+// the error directly wrapping base is read first). Within the new Tracer, the error directly wrapping base is treated
+// as the root cause, with a depth of zero. If base is the most recent error, the new Tracer has no errors. The state
+// of this Tracer is not modified. Returns an error if no error in the chain matches base.
+func (tracer *Tracer) SinceBase(base error) (*Tracer, error) {
+	if base == nil {
+		return nil, errors.New("base for SinceBase must not be nil")
+	}
+
+	for _, baseErr := range tracer.baseErrs {
+		for _, err := range tracer.chainOf(baseErr) {
+			if !errorMatchesBase(err, base) {
+				continue
+			}
+
+			options := append([]func(*Tracer) error{markClone}, tracer.optionFuncs...)
+			options = append(options, withChainBase(base))
+			subTracer, constructErr := newTracer([]error{baseErr}, options...)
+			if constructErr != nil {
+				return nil, xerrors.Errorf("could not construct Tracer for subchain: %w", constructErr)
+			}
+
+			return subTracer, nil
+		}
+	}
+
+	return nil, errors.New("no error in the chain matches the given base")
+}
+
+// withChainBase sets the chain base of the Tracer when passed to NewTracer, so that each chain ends just before the
+// first error that matches base.
+func withChainBase(base error) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.chainBase = base
+
+		return nil
+	}
+}
+
+// withChainTargets sets the chain targets of the Tracer when passed to NewTracer, so that each chain only holds the
+// errors that match one of targets.
+func withChainTargets(targets []error) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.chainTargets = targets
+
+		return nil
+	}
+}
+
+// Concat returns a new Tracer that traces all of the errors of this Tracer, followed by all of the errors of the other
+// Tracer, as if they were constructed together with NewMultiTracer. The new Tracer is rebuilt from the errors that each
+// Tracer was constructed with, so neither Tracer's state is modified, and the errors that have already been read from
+// either are still included. The new Tracer is constructed with this Tracer's options, which apply to the errors of
+// both Tracers; in particular, the ordering of this Tracer applies within each top-level error's chain, but this
+// Tracer's errors always come before the other Tracer's errors.
+func (tracer *Tracer) Concat(other *Tracer) (*Tracer, error) {
+	if other == nil {
+		return nil, errors.New("can not concatenate a nil Tracer")
+	}
+
+	baseErrs := make([]error, 0, len(tracer.baseErrs)+len(other.baseErrs))
+	baseErrs = append(baseErrs, tracer.baseErrs...)
+	baseErrs = append(baseErrs, other.baseErrs...)
+	concatenated, err := tracer.clone(baseErrs...)
+	if err != nil {
+		return nil, xerrors.Errorf("could not construct concatenated Tracer: %w", err)
+	}
+
+	return concatenated, nil
+}
+
+// errorMatchesTarget checks if the given error, without unwrapping, can be assigned to the target, in the same manner
+// as errors.As. If it can, the target will be set to the error.
+func errorMatchesTarget(err error, targetValue reflect.Value) bool {
+	targetType := targetValue.Type().Elem()
+	if reflect.TypeOf(err).AssignableTo(targetType) {
+		targetValue.Elem().Set(reflect.ValueOf(err))
+		return true
+	}
+
+	asErr, isAser := err.(interface{ As(interface{}) bool })
+
+	return isAser && asErr.As(targetValue.Interface())
+}
+
+// BaseError returns the error that the Tracer was constructed with, unmodified. If the Tracer was constructed with
+// NewMultiTracer with more than one error, nil is returned.
+func (tracer *Tracer) BaseError() error {
+	return tracer.baseErr
+}
+
+// Ordering returns the TraceOrderingMethod that the Tracer was constructed with, for the Read methods; if ReadOrdering
+// was set, this is its ordering. If the Tracer was constructed with StableOrderingFunc, this is OldestFirstOrdering, as
+// that is the order used for errors with equal keys.
+func (tracer *Tracer) Ordering() TraceOrderingMethod {
+	return tracer.ordering
+}
+
 // Read implements the io.Reader interface. Will read up to len(dest) bytes of the current error.
 // Note that this means dest will only be filled up the contents of the error, regardless of if there are other errors
 // to be read in the error stack.
@@ -94,13 +541,15 @@ func (tracer *Tracer) Read(dest []byte) (n int, err error) {
 	tracer.readMux.Lock()
 	defer tracer.readMux.Unlock()
 
-	if tracer.buffer.Len() == 0 && len(tracer.errorChain) == 0 {
-		return 0, io.EOF
-	} else if tracer.buffer.Len() == 0 {
-		message := generateErrorString(tracer.popChain(), tracer.formatter, tracer.detailedOutput)
-		// If we are passed a zero length error, returning an io.EOF is not appropriate.
-		if len(message) == 0 {
-			message = emptyError
+	if tracer.buffer.Len() == 0 {
+		message, err := tracer.nextMessage()
+		if err != nil {
+			return 0, err
+		}
+
+		if tracer.withBOM && !tracer.bomRead {
+			tracer.buffer.WriteString(byteOrderMark)
+			tracer.bomRead = true
 		}
 
 		tracer.buffer.WriteString(message)
@@ -112,52 +561,527 @@ func (tracer *Tracer) Read(dest []byte) (n int, err error) {
 // ReadNext will read one unwrapped error and its associated trace
 // If Read() has been called, but the buffer has not been exhausted, its contents will be discarded.
 // Returns io.EOF when there are no more errors to read, but notably will not be returned when the last error is
-// returned.
+// returned, so a consumer must read until io.EOF is returned with an empty message (see IsEnd). If the
+// EOFWithLastMessage option is set, io.EOF is instead returned along with the last error's message, following the
+// convention of io.Reader; in that case, the message must be used even when io.EOF is returned.
 func (tracer *Tracer) ReadNext() (string, error) {
+	return tracer.readNext(tracer.eofWithLastMessage)
+}
+
+// readNext is identical to ReadNext, but will only return io.EOF along with the last message if eofWithLastMessage is
+// set, regardless of the Tracer's options.
+func (tracer *Tracer) readNext(eofWithLastMessage bool) (string, error) {
 	tracer.readMux.Lock()
 	defer tracer.readMux.Unlock()
 
 	tracer.buffer.Reset()
-	if len(tracer.errorChain) == 0 {
-		return "", io.EOF
+	message, err := tracer.nextMessage()
+	if err == nil && eofWithLastMessage && len(tracer.errorChain) == 0 {
+		return message, io.EOF
 	}
 
-	message := generateErrorString(tracer.popChain(), tracer.formatter, tracer.detailedOutput)
-	if len(message) == 0 {
-		return emptyError, nil
+	return message, err
+}
+
+// IsEnd checks whether or not the given error, as returned by Read or ReadNext, indicates that there are no more errors
+// to read from the Tracer (i.e. that it is io.EOF).
+func IsEnd(err error) bool {
+	return err == io.EOF
+}
+
+// SeekTo rebuilds the chain from the errors the Tracer was constructed with, and discards the first index errors in the
+// Tracer's ordering, so that the next read will start at the error at the given index. Any contents of the current
+// error being read by Read are discarded, and if the Tracer's formatter is Resettable, it is reset. Returns an error if
+// there is no error at the given index.
+func (tracer *Tracer) SeekTo(index int) error {
+	tracer.readMux.Lock()
+	defer tracer.readMux.Unlock()
+
+	tracer.rebuildChain()
+	tracer.buffer.Reset()
+	if resettableFormatter, isResettable := tracer.formatter.(Resettable); isResettable {
+		resettableFormatter.Reset()
+	}
+
+	numErrors := tracer.remainingErrorCount()
+	if index < 0 || index >= numErrors {
+		return xerrors.Errorf("can not seek to index %d in a trace of %d errors", index, numErrors)
+	}
+
+	for i := 0; i < index; i++ {
+		tracer.popChain()
+	}
+
+	return nil
+}
+
+// At returns the formatted message of the error at the given index in the Tracer's ordering, without consuming any
+// errors from the Tracer. Each call formats the errors from the start of the trace up to the given index, so that
+// stateful formatters produce the same output as they would in a full trace; as such, if the Tracer's formatter is
+// Resettable, it is reset. Returns an error if there is no error at the given index, or if the formatter drops it.
+func (tracer *Tracer) At(index int) (string, error) {
+	clone, err := tracer.clone(tracer.baseErrs...)
+	if err != nil {
+		return "", xerrors.Errorf("failed to recreate Tracer for random access: %w", err)
+	}
+
+	numErrors := clone.remainingErrorCount()
+	if index < 0 || index >= numErrors {
+		return "", xerrors.Errorf("no error at index %d in a trace of %d errors", index, numErrors)
+	}
+
+	if resettableFormatter, isResettable := clone.formatter.(Resettable); isResettable {
+		resettableFormatter.Reset()
+	}
+
+	for i := 0; i < index; i++ {
+		clone.formatError(clone.popChain())
+	}
+
+	message, dropped := clone.formatError(clone.popChain())
+	if dropped {
+		return "", xerrors.Errorf("error at index %d was dropped by the formatter", index)
 	}
 
 	return message, nil
 }
 
-// popChain will pop the next error off the error chain
-func (tracer *Tracer) popChain() (storedError error) {
-	if tracer.ordering == OldestFirstOrdering {
-		storedError = tracer.errorChain[len(tracer.errorChain)-1]
-		tracer.errorChain = tracer.errorChain[:len(tracer.errorChain)-1]
-	} else {
-		storedError = tracer.errorChain[0]
-		tracer.errorChain = tracer.errorChain[1:]
+// remainingErrorCount counts the errors that have yet to be popped off of the error chain, including those of the
+// chains that have not yet been started.
+func (tracer *Tracer) remainingErrorCount() int {
+	numErrors := len(tracer.errorChain)
+	for _, chain := range tracer.pendingChains {
+		numErrors += len(chain)
+	}
+
+	return numErrors
+}
+
+// Exhausted checks whether or not all of the errors in the Tracer have been read, including the contents of the current
+// error being read by Read. Note that if the remaining errors would all be dropped by the formatter, the Tracer is not
+// considered exhausted, even though the next read will return io.EOF.
+func (tracer *Tracer) Exhausted() bool {
+	tracer.readMux.Lock()
+	defer tracer.readMux.Unlock()
+
+	return len(tracer.errorChain) == 0 && tracer.buffer.Len() == 0
+}
+
+// nextMessage pops errors off the error chain until one is found that has not been dropped by the formatter, and
+// returns its output. Returns io.EOF if there are no more errors to read.
+func (tracer *Tracer) nextMessage() (string, error) {
+	for len(tracer.errorChain) > 0 {
+		message, dropped := tracer.formatError(tracer.popChain())
+		if !dropped {
+			return message, nil
+		}
+	}
+
+	return "", io.EOF
+}
+
+// formatError produces the output of the given error at the given position in the trace, as configured by the Tracer's
+// options. If the formatter dropped the error, dropped will be true.
+func (tracer *Tracer) formatError(err error, context TraceContext) (message string, dropped bool) {
+	var formatter TraceFormatter = tracer.formatter
+	if tracer.onFormat != nil {
+		formatter = observedFormatter{formatter: tracer.formatter, hook: tracer.onFormat}
+	}
+
+	var formatStart time.Time
+	if tracer.onErrorTimed != nil {
+		formatStart = time.Now()
+	}
+
+	message = generateErrorString(err, errorStringOptions{
+		traceFormatter:  formatter,
+		context:         context,
+		detail:          tracer.wantsDetail(err, context),
+		wrapPrinter:     tracer.wrapPrinter,
+		detailSeparator: tracer.detailSeparator,
+		stripPrefix:     tracer.commonPrefix,
+		sourceLines:     tracer.sourceLines,
+		verboseFallback: tracer.verboseFallback,
+		frameWidth:      tracer.frameWidth,
+	})
+	if tracer.onErrorTimed != nil {
+		tracer.onErrorTimed(context.Index, time.Since(formatStart))
+	}
+
+	if strings.Contains(message, Dropped) {
+		return "", true
+	}
+
+	// If we are passed a zero length error, returning an io.EOF from Read is not appropriate.
+	if len(message) == 0 {
+		message = tracer.messages.Empty
+	}
+
+	if tracer.showHash {
+		message = fmt.Sprintf("[%s] %s", hashError(err), message)
+	}
+
+	return message, false
+}
+
+// observedFormatter is a ContextualTraceFormatter that passes each message to another formatter, and reports each call
+// to a hook, as set by OnFormat.
+type observedFormatter struct {
+	formatter TraceFormatter
+	hook      func(previous []string, message, formatted string)
+}
+
+// FormatTrace passes the message to the wrapped formatter and reports the call to the hook.
+func (formatter observedFormatter) FormatTrace(previousMessages []string, message string) string {
+	return formatter.FormatTraceWithContext(TraceContext{}, previousMessages, message)
+}
+
+// FormatTraceWithContext passes the message to the wrapped formatter and reports the call to the hook.
+func (formatter observedFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previousMessages []string,
+	message string,
+) string {
+	formatted := formatWithContext(formatter.formatter, context, previousMessages, message)
+	formatter.hook(previousMessages, message, formatted)
+
+	return formatted
+}
+
+// wantsDetail checks whether or not the given error should be outputted with detail at the given position.
+func (tracer *Tracer) wantsDetail(err error, context TraceContext) bool {
+	if !tracer.detailedOutput || (tracer.detailDepth != 0 && context.Depth >= tracer.detailDepth) {
+		return false
+	}
+
+	errType := reflect.TypeOf(err)
+	for _, suppressedType := range tracer.suppressedDetailTypes {
+		if errType == suppressedType {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Root formats and returns only the root cause of the traced error, using the Tracer's formatter. No errors are
+// consumed from the Tracer. The root cause is formatted with a clone of the formatter, so a stateful formatter is left
+// as it was, and what is read from the Tracer afterwards does not change; a stateful formatter that is not Cloneable
+// can not be copied, and so is shared, as with Trace. For a Tracer constructed with NewMultiTracer, this is the root
+// cause of the first non-nil top-level error. Returns io.EOF if there are no errors to trace, or if the formatter drops
+// the root cause.
+func (tracer *Tracer) Root() (string, error) {
+	return tracer.formatChainEnd(false)
+}
+
+// Top formats and returns only the top-level error of the trace, i.e. the most recent error, which wraps all others,
+// using the Tracer's formatter. This is the error that the Tracer was constructed with, and the opposite end of the
+// chain from Root, regardless of the Tracer's ordering. As with Root, no errors are consumed from the Tracer, and the
+// error is formatted with a clone of the formatter. For a Tracer constructed with NewMultiTracer, this is the first
+// non-nil top-level error. Returns io.EOF if there are no errors to trace, or if the formatter drops the top-level
+// error.
+func (tracer *Tracer) Top() (string, error) {
+	return tracer.formatChainEnd(true)
+}
+
+// formatChainEnd formats either the top-level error or the root cause of the first non-empty chain, as dictated by
+// the contracts for Top and Root, respectively.
+func (tracer *Tracer) formatChainEnd(top bool) (string, error) {
+	// The formatter may be in the middle of a trace, or shared with other Tracers, so a fresh copy of it is used.
+	isolated, err := tracer.clone(tracer.baseErrs...)
+	if err != nil {
+		return "", xerrors.Errorf("failed to recreate Tracer for formatting: %w", err)
+	}
+
+	isolated.formatter = cloneFormatter(isolated.formatter)
+	for baseErrIndex, baseErr := range tracer.baseErrs {
+		chain := tracer.chainOf(baseErr)
+		if len(chain) == 0 {
+			continue
+		}
+
+		err := chain[len(chain)-1]
+		depth := 0
+		if top {
+			err = chain[0]
+			depth = len(chain) - 1
+		}
+
+		context := TraceContext{Depth: depth, Index: 0, Group: 0, ChainLength: len(chain), Err: err}
+		links := tracer.orderChain(chain)
+		for i, link := range links {
+			if link.depth == depth {
+				context.Index = i
+				context.Wrapper = link.wrapper
+				context.Fields = tracer.fields
+				context.Messages = tracer.messages
+				context.Chain = chain
+				context.Last = i == len(links)-1 && !hasNonNilError(tracer.baseErrs[baseErrIndex+1:])
+				break
+			}
+		}
+
+		message, dropped := isolated.formatError(err, context)
+		if dropped {
+			return "", io.EOF
+		}
+
+		return message, nil
+	}
+
+	return "", io.EOF
+}
+
+// Walk calls fn with each error in the full trace and its depth within its chain (see TraceContext), in the Tracer's
+// ordering, stopping early if fn returns false. The errors are not formatted, and none are consumed from the Tracer;
+// all of the errors the Tracer was constructed with are walked, regardless of how many have been read. Errors dropped
+// by the formatter are included, as they are only dropped once formatted. The returned error is currently always nil.
+func (tracer *Tracer) Walk(fn func(depth int, err error) bool) error {
+	for _, baseErr := range tracer.baseErrs {
+		for _, link := range tracer.orderChain(tracer.chainOf(baseErr)) {
+			if !fn(link.depth, link.err) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// Path renders the full trace on a single line, as the messages of its errors joined by the given arrow, from the root
+// cause to the error that wraps all others (e.g. Path(" << ") gives "things broke :( << aw shucks"). This order does
+// not depend on the Tracer's ordering. The messages are the raw messages of the errors, without any detailed output or
+// formatting, and each line of a message that spans multiple lines is joined to the next with a space. For a Tracer
+// constructed with NewMultiTracer, the path of each top-level error is joined to the next with "; ". All of the errors
+// the Tracer was constructed with are included, regardless of how many have been read, and no errors are consumed.
+func (tracer *Tracer) Path(arrow string) string {
+	paths := []string{}
+	for _, baseErr := range tracer.baseErrs {
+		chain := tracer.chainOf(baseErr)
+		if len(chain) == 0 {
+			continue
+		}
+
+		messages := make([]string, len(chain))
+		for i, err := range chain {
+			messages[len(chain)-i-1] = strings.Join(strings.Fields(plainMessage(err)), " ")
+		}
+
+		paths = append(paths, strings.Join(messages, arrow))
+	}
+
+	return strings.Join(paths, "; ")
+}
+
+// TypeCounts counts the number of errors of each concrete type in the full trace, keyed by the name of the type (e.g.
+// "*errors.errorString"). All of the errors the Tracer was constructed with are counted, regardless of how many have
+// been read, and no errors are consumed from the Tracer. Nil errors, such as those passed to NewMultiTracer, are not
+// part of the trace and so are not counted.
+func (tracer *Tracer) TypeCounts() map[string]int {
+	counts := map[string]int{}
+	for _, baseErr := range tracer.baseErrs {
+		for _, err := range tracer.chainOf(baseErr) {
+			counts[reflect.TypeOf(err).String()]++
+		}
+	}
+
+	return counts
+}
+
+// hasNonNilError checks if any of the given errors are not nil.
+func hasNonNilError(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TraceFunc reads each remaining error and calls fn with its index in the trace and its formatted message, using the
+// Tracer's formatter and ordering. Unlike Trace, this does not clone the Tracer, so all of the remaining errors are
+// consumed.
+func (tracer *Tracer) TraceFunc(fn func(index int, message string)) error {
+	for index := 0; ; index++ {
+		message, err := tracer.readNext(false)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("could not read trace: %w", err)
+		}
+
+		fn(index, message)
+	}
+}
+
+// WriteFramed reads each remaining error and writes its formatted message to the given io.Writer as a frame, which
+// consists of the length of the message in bytes, as a 4-byte big-endian unsigned integer, followed by the message
+// itself, encoded as UTF-8. Unlike Trace, no separators are written between the errors, so a receiver can tell where
+// each message ends even if it contains newlines. Like TraceFunc, this does not clone the Tracer, so all of the
+// remaining errors are consumed.
+func (tracer *Tracer) WriteFramed(writer io.Writer) error {
+	for {
+		message, err := tracer.readNext(false)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("could not read trace: %w", err)
+		}
+
+		frame := make([]byte, 4+len(message))
+		binary.BigEndian.PutUint32(frame, uint32(len(message)))
+		copy(frame[4:], message)
+		_, err = writer.Write(frame)
+		if err != nil {
+			return xerrors.Errorf("failed to write frame to writer: %w", err)
+		}
+	}
+}
+
+// EncodeJSON reads each remaining error and encodes their formatted messages with the given json.Encoder, as a single
+// JSON array of strings (e.g. ["things broke :(","aw shucks"]), allowing the trace to be written as one value within a
+// larger JSON stream. As json.Encoder can only encode complete values, the messages are collected before the array is
+// encoded; only the messages themselves are held in memory, not the encoded output. Like TraceFunc, this does not clone
+// the Tracer, so all of the remaining errors are consumed. If there are no errors to read, an empty array is encoded.
+func (tracer *Tracer) EncodeJSON(encoder *json.Encoder) error {
+	messages := []string{}
+	for {
+		message, err := tracer.readNext(false)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return xerrors.Errorf("could not read trace: %w", err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	err := encoder.Encode(messages)
+	if err != nil {
+		return xerrors.Errorf("failed to encode trace: %w", err)
+	}
+
+	return nil
+}
+
+// Chan reads each remaining error in a new goroutine, and sends each formatted message on the returned channel, which
+// is closed once all of the errors have been read. Like TraceFunc, all of the remaining errors are consumed. The
+// goroutine will only finish once the channel has been fully drained; if the caller may stop receiving early, use
+// ChanContext instead, to avoid leaking the goroutine.
+func (tracer *Tracer) Chan() <-chan string {
+	return tracer.ChanContext(context.Background())
+}
+
+// ChanContext behaves like Chan, but will stop reading errors and close the returned channel once ctx is done. Note
+// that a message may be consumed from the Tracer but never sent if ctx is done while it is waiting to be received.
+func (tracer *Tracer) ChanContext(ctx context.Context) <-chan string {
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		for {
+			message, err := tracer.readNext(false)
+			if err != nil {
+				return
+			}
+
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return messages
+}
+
+// popChain will pop the next error off the error chain, along with its position in the trace.
+func (tracer *Tracer) popChain() (storedError error, context TraceContext) {
+	link := tracer.errorChain[0]
+	tracer.errorChain = tracer.errorChain[1:]
+	storedError = link.err
+	context.Index = tracer.readCount
+	context.Depth = link.depth
+	context.ChainLength = tracer.chainLength
+	context.Wrapper = link.wrapper
+	context.Fields = tracer.fields
+	context.Messages = tracer.messages
+	context.Chain = tracer.currentChain
+	context.Group = tracer.groupCount - 1
+	context.Last = len(tracer.errorChain) == 0 && len(tracer.pendingChains) == 0
+	context.Err = storedError
+
+	tracer.readCount++
+	if len(tracer.errorChain) == 0 {
+		tracer.advanceChain()
 	}
 
 	return
 }
 
+// advanceChain will replace the error chain with the next pending chain, if there is one.
+func (tracer *Tracer) advanceChain() {
+	if len(tracer.pendingChains) == 0 {
+		return
+	}
+
+	tracer.errorChain = tracer.orderChain(tracer.pendingChains[0])
+	tracer.chainLength = len(tracer.errorChain)
+	tracer.currentChain = tracer.pendingChains[0]
+	tracer.pendingChains = tracer.pendingChains[1:]
+	tracer.groupCount++
+}
+
+// orderChain arranges the given chain, which holds the originating error at len(chain) - 1, in the order that its
+// errors should be read.
+func (tracer *Tracer) orderChain(chain []error) []chainLink {
+	links := make([]chainLink, len(chain))
+	for i, err := range chain {
+		links[i] = chainLink{err: err, depth: len(chain) - i - 1}
+		if i > 0 {
+			links[i].wrapper = chain[i-1]
+		}
+	}
+
+	if tracer.ordering == NewestFirstOrdering && tracer.orderingKey == nil {
+		return links
+	}
+
+	for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+		links[i], links[j] = links[j], links[i]
+	}
+
+	if tracer.orderingKey != nil {
+		sort.SliceStable(links, func(i, j int) bool {
+			return tracer.orderingKey(links[i].err) < tracer.orderingKey(links[j].err)
+		})
+	}
+
+	return links
+}
+
 // Format allows for tracer to implement fmt.Formatter. This will simply make a clone of the tracer
 // and print out the full trace. DetailedOutput will be given when %+v is provided, and normal output
-// when %v is provided.
+// when %v is provided. When %#v is provided, a Go-syntax representation of the non-detailed messages of the trace is
+// given (e.g. &xtrace.Tracer{errors:[]string{"things broke :(", "aw shucks"}}).
 func (tracer *Tracer) Format(s fmt.State, verb rune) {
 	if verb != 'v' {
 		return
 	}
 
-	clone, err := NewTracer(tracer.baseErr, tracer.optionFuncs...)
+	clone, err := tracer.clone(tracer.baseErrs...)
 	if err != nil {
 		out := fmt.Sprintf("<could not print trace: %s>", err)
 		io.WriteString(s, out)
 		return
 	}
 
+	if s.Flag('#') {
+		clone.goSyntax(s)
+		return
+	}
+
 	clone.detailedOutput = s.Flag('+')
 	err = clone.trace(s)
 	if err != nil {
@@ -167,19 +1091,353 @@ func (tracer *Tracer) Format(s fmt.State, verb rune) {
 	}
 }
 
+// goSyntax writes a Go-syntax representation of the remaining messages in the Tracer to the given io.Writer.
+func (tracer *Tracer) goSyntax(writer io.Writer) {
+	tracer.detailedOutput = false
+	messages := []string{}
+	err := tracer.TraceFunc(func(index int, message string) {
+		messages = append(messages, message)
+	})
+	if err != nil {
+		out := fmt.Sprintf("<%s>", err)
+		io.WriteString(writer, out)
+		return
+	}
+
+	out := fmt.Sprintf("&xtrace.Tracer{errors:%#v}", messages)
+	io.WriteString(writer, out)
+}
+
 // Trace makes a clone of the Tracer and writes the full trace to the provided io.Writer.
 func (tracer *Tracer) Trace(writer io.Writer) error {
-	clone, err := NewTracer(tracer.baseErr, tracer.optionFuncs...)
+	clone, err := tracer.clone(tracer.baseErrs...)
+	if err != nil {
+		return xerrors.Errorf("failed to recreate Tracer for re-tracing: %w", err)
+	}
+
+	return clone.trace(writer)
+}
+
+// TraceMatching makes a clone of the Tracer that only holds the errors that match at least one of the given targets,
+// and writes its full trace to the provided io.Writer, such as for extracting the errors of interest for an alert. An
+// error matches a target if it is the target, or its Is method reports that it matches the target, as with errors.Is;
+// the errors it wraps are not considered, as otherwise every error that wraps a matching error would match as well.
+// The errors that do not match are left out of the trace as if they were not in the chain at all, so the depth of
+// each error given to the formatter is its position among the matching errors. If no error matches, nothing is
+// written.
+func (tracer *Tracer) TraceMatching(writer io.Writer, targets ...error) error {
+	options := append([]func(*Tracer) error{markClone}, tracer.optionFuncs...)
+	// A nil slice would disable matching entirely, rather than match nothing.
+	options = append(options, withChainTargets(append([]error{}, targets...)))
+	clone, err := newTracer(tracer.baseErrs, options...)
+	if err != nil {
+		return xerrors.Errorf("failed to recreate Tracer for tracing matching errors: %w", err)
+	}
+
+	return clone.trace(writer)
+}
+
+// TraceN is identical to Trace, but also returns the number of bytes written to the provided io.Writer, following the
+// conventions of io.WriterTo, such as for recording the size of traces. If writing fails, the number of bytes written
+// before the failure is returned alongside the error.
+func (tracer *Tracer) TraceN(writer io.Writer) (int64, error) {
+	counter := &countingWriter{writer: writer}
+	err := tracer.Trace(counter)
+
+	return counter.count, err
+}
+
+// TraceDetailed makes a clone of the Tracer and writes the full trace to the provided io.Writer with detailed output,
+// regardless of the DetailedOutput option. This mirrors formatting the Tracer with %+v.
+func (tracer *Tracer) TraceDetailed(writer io.Writer) error {
+	return tracer.traceWithDetail(writer, true)
+}
+
+// TraceTerse makes a clone of the Tracer and writes the full trace to the provided io.Writer without detailed output,
+// regardless of the DetailedOutput option. This mirrors formatting the Tracer with %v.
+func (tracer *Tracer) TraceTerse(writer io.Writer) error {
+	return tracer.traceWithDetail(writer, false)
+}
+
+// traceWithDetail makes a clone of the Tracer and writes the full trace to the provided io.Writer, with or without
+// detailed output, as requested.
+func (tracer *Tracer) traceWithDetail(writer io.Writer, detailed bool) error {
+	clone, err := tracer.clone(tracer.baseErrs...)
 	if err != nil {
 		return xerrors.Errorf("failed to recreate Tracer for re-tracing: %w", err)
 	}
 
+	clone.detailedOutput = detailed
+
 	return clone.trace(writer)
 }
 
-// trace is identical to Trace, but does not clone the Tracer.
+// hybridMarker separates the human-readable and machine-readable sections of the output of TraceHybrid.
+const hybridMarker = "--- xtrace json ---"
+
+// hybridEntry is a single error within the machine-readable section of the output of TraceHybrid.
+type hybridEntry struct {
+	Depth   int    `json:"depth"`
+	Message string `json:"message"`
+}
+
+// TraceHybrid makes a clone of the Tracer and writes the full trace to the provided io.Writer, followed by a
+// machine-readable copy of the same trace. The output is laid out as follows:
+//
+//	<the full trace, as written by Trace>
+//	--- xtrace json ---
+//	{"errors":[{"depth":0,"message":"things broke :("},{"depth":1,"message":"aw shucks"}]}
+//
+// The machine-readable section is a single line of JSON, terminated by a newline, holding the depth of each error
+// within its chain (see TraceContext) and its message, in the Tracer's ordering. These messages are neither formatted
+// nor detailed, and include errors that the formatter drops from the full trace. If any fields were attached with
+// WithField, they are also included, under "fields".
+func (tracer *Tracer) TraceHybrid(writer io.Writer) error {
+	clone, err := tracer.clone(tracer.baseErrs...)
+	if err != nil {
+		return xerrors.Errorf("failed to recreate Tracer for re-tracing: %w", err)
+	}
+
+	entries := []hybridEntry{}
+	for len(clone.errorChain) > 0 {
+		err, context := clone.popChain()
+		entries = append(entries, hybridEntry{Depth: context.Depth, Message: strings.TrimSpace(plainMessage(err))})
+	}
+
+	builder := strings.Builder{}
+	err = tracer.Trace(&builder)
+	if err != nil {
+		return xerrors.Errorf("failed to write trace: %w", err)
+	} else if builder.Len() > 0 {
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(hybridMarker + "\n")
+	encoder := json.NewEncoder(&builder)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(struct {
+		Fields map[string]interface{} `json:"fields,omitempty"`
+		Errors []hybridEntry          `json:"errors"`
+	}{Fields: tracer.fields, Errors: entries})
+	if err != nil {
+		return xerrors.Errorf("failed to encode trace: %w", err)
+	}
+
+	_, err = io.WriteString(writer, builder.String())
+	if err != nil {
+		return xerrors.Errorf("failed to write trace to writer: %w", err)
+	}
+
+	return nil
+}
+
+// Base64Reader makes a clone of the Tracer and returns an io.Reader that holds the full trace, as written by Trace,
+// encoded with the given base64 encoding, such as for embedding the trace in a header. base64.StdEncoding is used if
+// encoding is nil; base64.URLEncoding or base64.RawURLEncoding may be given for contexts where "+" and "/" are not
+// allowed. The trace is produced and encoded up front, so the returned reader holds all of it in memory. If the trace
+// can not be produced, reading from the returned reader gives the error.
+func (tracer *Tracer) Base64Reader(encoding *base64.Encoding) io.Reader {
+	if encoding == nil {
+		encoding = base64.StdEncoding
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	encoder := base64.NewEncoder(encoding, buffer)
+	err := tracer.Trace(encoder)
+	if err != nil {
+		return errorReader{err: xerrors.Errorf("failed to write trace: %w", err)}
+	}
+
+	// Closing flushes any partially encoded block, and can not fail when writing to a bytes.Buffer.
+	encoder.Close()
+
+	return buffer
+}
+
+// errorReader is an io.Reader that always fails with the given error.
+type errorReader struct {
+	err error
+}
+
+// Read implements io.Reader, returning the reader's error.
+func (reader errorReader) Read(dest []byte) (int, error) {
+	return 0, reader.err
+}
+
+// AsError makes a clone of the Tracer and returns an error whose Error method returns its full trace, as written by
+// Trace, but which still wraps the traced error, so that errors.Is, errors.As, and errors.Unwrap see through it to the
+// original chain. This allows an error to be replaced with its formatted form, such as before returning it up the
+// stack, without losing the ability to inspect it. For a Tracer constructed with NewMultiTracer, errors.Is and
+// errors.As check each of the top-level errors, though errors.Unwrap gives nil. Returns nil if there are no errors to
+// trace.
+//
+// Note that, unlike most errors, the message of the returned error generally spans multiple lines, which may be
+// unexpected by code that logs errors on a single line, or that wraps it further (e.g. "request failed: things broke
+// :(\naw shucks"). The trace is produced up front, so the message does not change if the Tracer is read from later.
+// If the trace can not be produced, the message of the traced error is used instead.
+func (tracer *Tracer) AsError() error {
+	if !hasNonNilError(tracer.baseErrs) {
+		return nil
+	}
+
+	builder := strings.Builder{}
+	err := tracer.Trace(&builder)
+	if err != nil {
+		return &tracedError{message: joinErrorMessages(tracer.baseErrs), baseErrs: tracer.baseErrs}
+	}
+
+	return &tracedError{message: builder.String(), baseErrs: tracer.baseErrs}
+}
+
+// tracedError is the error produced by AsError.
+type tracedError struct {
+	message  string
+	baseErrs []error
+}
+
+// joinErrorMessages joins the messages of the given non-nil errors with newlines.
+func joinErrorMessages(errs []error) string {
+	messages := []string{}
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Error implements the error interface, returning the full trace.
+func (err *tracedError) Error() string {
+	return err.message
+}
+
+// Unwrap returns the traced error, or nil if there was more than one top-level error.
+func (err *tracedError) Unwrap() error {
+	if len(err.baseErrs) != 1 {
+		return nil
+	}
+
+	return err.baseErrs[0]
+}
+
+// Is checks whether or not any of the top-level errors match the target, as with errors.Is.
+func (err *tracedError) Is(target error) bool {
+	for _, baseErr := range err.baseErrs {
+		if baseErr != nil && xerrors.Is(baseErr, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error in the chains of the top-level errors that matches the target, as with errors.As.
+func (err *tracedError) As(target interface{}) bool {
+	for _, baseErr := range err.baseErrs {
+		if baseErr != nil && xerrors.As(baseErr, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TraceStringBuilder makes a clone of the Tracer and returns the full trace as a string. The trace is built with a
+// strings.Builder, so the resulting string is not copied after the trace is produced.
+func (tracer *Tracer) TraceStringBuilder() (string, error) {
+	builder := strings.Builder{}
+	err := tracer.Trace(&builder)
+	if err != nil {
+		return "", xerrors.Errorf("failed to trace into string: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
+// Size makes a clone of the Tracer and computes the number of bytes that the full trace would take up, as written by
+// Trace, without holding the trace in memory, such as for sizing a buffer or enforcing a limit before writing the
+// trace. The configured formatter and separators are used, so the size is exact, unless the formatter's output varies
+// between traces (e.g. TimingFormatter), in which case it is only an estimate of what a later call to Trace produces.
+func (tracer *Tracer) Size() (int, error) {
+	n, err := tracer.TraceN(ioutil.Discard)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to measure trace: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// trace is identical to Trace, but does not clone the Tracer. If TraceOrdering was set, the chain is rebuilt in its
+// order, so this must only be used on a Tracer that has not been read from.
 func (tracer *Tracer) trace(writer io.Writer) error {
-	err := tracer.writeRemainingErrors(writer)
+	// A DedupeSink must be given the trace as a whole, so that it can be suppressed as a whole.
+	if sink, isSink := writer.(*DedupeSink); isSink {
+		return sink.traceFrom(tracer, "")
+	}
+
+	if tracer.traceOrdering != nil && *tracer.traceOrdering != tracer.ordering {
+		tracer.ordering = *tracer.traceOrdering
+		tracer.rebuildChain()
+	}
+
+	if resettableFormatter, isResettable := tracer.formatter.(Resettable); isResettable {
+		resettableFormatter.Reset()
+	}
+
+	// The byte order mark must come before anything else, so it is neither prefixed nor counted towards MaxBytes.
+	if tracer.withBOM && len(tracer.errorChain) > 0 {
+		_, err := io.WriteString(writer, byteOrderMark)
+		if err != nil {
+			return xerrors.Errorf("failed to write byte order mark to writer: %w", err)
+		}
+	}
+
+	if tracer.linePrefix != nil {
+		writer = &linePrefixWriter{writer: writer, prefix: tracer.linePrefix()}
+	}
+
+	if tracer.maxLines > 0 {
+		writer = &lineTruncatingWriter{writer: writer, remaining: tracer.maxLines, marker: tracer.messages.Truncated}
+	}
+
+	if tracer.maxBytes > 0 {
+		writer = &truncatingWriter{writer: writer, remaining: tracer.maxBytes, marker: tracer.messages.Truncated}
+	}
+
+	// Converting the line endings last keeps them consistent across everything written, including the newlines between
+	// errors, without changing how lines are counted.
+	if ending := tracer.lineEnding(); ending != "\n" {
+		writer = &lineEndingWriter{writer: writer, ending: ending}
+	}
+
+	writeErrors := tracer.writeRemainingErrors
+	if tracer.rawBlock {
+		writeErrors = tracer.writeRawErrors
+	}
+
+	if len(tracer.fields) > 0 && len(tracer.errorChain) > 0 {
+		err := tracer.writeFields(writer)
+		if err != nil {
+			return xerrors.Errorf("failed to write fields to writer: %w", err)
+		}
+	}
+
+	if tracer.showCount && len(tracer.errorChain) > 0 {
+		_, err := io.WriteString(writer, tracer.countLine()+"\n")
+		if err != nil {
+			return xerrors.Errorf("failed to write error count to writer: %w", err)
+		}
+	}
+
+	if !tracer.rawBlock && tracer.commonPrefix != "" && len(tracer.errorChain) > 0 {
+		_, err := io.WriteString(writer, strings.TrimSpace(tracer.commonPrefix)+"\n")
+		if err != nil {
+			return xerrors.Errorf("failed to write common prefix to writer: %w", err)
+		}
+	}
+
+	err := writeErrors(writer)
 	if err != nil {
 		return xerrors.Errorf("failed to write trace to writer: %w", err)
 	}
@@ -187,19 +1445,113 @@ func (tracer *Tracer) trace(writer io.Writer) error {
 	return nil
 }
 
+// lineEnding gets the line ending that the full trace is written with, as given by the formatter if it is a
+// LineEndingFormatter.
+func (tracer *Tracer) lineEnding() string {
+	if lineEndingFormatter, isLineEndingFormatter := tracer.formatter.(LineEndingFormatter); isLineEndingFormatter {
+		return lineEndingFormatter.LineEnding()
+	}
+
+	return "\n"
+}
+
+// countLine produces the line written by the ShowCount option, holding the number of errors left in the Tracer.
+func (tracer *Tracer) countLine() string {
+	numErrors := tracer.remainingErrorCount()
+	if numErrors == 1 {
+		return tracer.messages.CountOne
+	}
+
+	return fmt.Sprintf(tracer.messages.Count, numErrors)
+}
+
+// writeFields writes the header that holds the fields attached with WithField to the given io.Writer, as produced by
+// the formatter if it is a FieldsFormatter.
+func (tracer *Tracer) writeFields(writer io.Writer) error {
+	header := formatFieldsLogfmt(tracer.fields)
+	if fieldsFormatter, isFieldsFormatter := tracer.formatter.(FieldsFormatter); isFieldsFormatter {
+		header = fieldsFormatter.FormatFields(tracer.fields)
+	}
+
+	if header == "" {
+		return nil
+	}
+
+	_, err := io.WriteString(writer, header+"\n")
+
+	return err
+}
+
 // writeRemainingErrors will write all errors left in the tracer to the given io.Writer
 func (tracer *Tracer) writeRemainingErrors(writer io.Writer) error {
 	lastOutput := ""
 	for {
-		out, err := tracer.ReadNext()
+		startsGroup := tracer.startsGroup()
+		out, err := tracer.readNext(false)
 		if err != nil && err != io.EOF {
 			return xerrors.Errorf("could not read trace: %w", err)
+		} else if err == io.EOF && lastOutput == "" {
+			return nil
+		} else if err == io.EOF && tracer.keepTrailing {
+			io.WriteString(writer, lastOutput)
+			return nil
 		} else if err == io.EOF {
-			io.WriteString(writer, lastOutput[:len(lastOutput)-1])
+			io.WriteString(writer, strings.TrimSuffix(lastOutput, "\n"))
 			return nil
 		} else {
 			io.WriteString(writer, lastOutput)
+			if startsGroup {
+				io.WriteString(writer, tracer.groupSeparator)
+			}
+
+			// There's no sense in reading any further if nothing else will be written
+			if isTruncated(writer) {
+				return nil
+			}
+
 			lastOutput = out + "\n"
 		}
 	}
 }
+
+// writeRawErrors will write the unformatted message of each error left in the tracer to the given io.Writer, separated
+// by newlines.
+func (tracer *Tracer) writeRawErrors(writer io.Writer) error {
+	tracer.readMux.Lock()
+	defer tracer.readMux.Unlock()
+
+	isFirst := true
+	for ; len(tracer.errorChain) > 0; isFirst = false {
+		startsGroup := tracer.startsGroup()
+		err, _ := tracer.popChain()
+		if !isFirst {
+			io.WriteString(writer, "\n")
+		}
+
+		if startsGroup {
+			io.WriteString(writer, tracer.groupSeparator)
+		}
+
+		if tracer.detailedOutput {
+			fmt.Fprintf(writer, "%+v", err)
+		} else {
+			io.WriteString(writer, err.Error())
+		}
+
+		// There's no sense in reading any further if nothing else will be written
+		if isTruncated(writer) {
+			return nil
+		}
+	}
+
+	if tracer.keepTrailing && !isFirst {
+		io.WriteString(writer, "\n")
+	}
+
+	return nil
+}
+
+// startsGroup checks if the next error to be read is the first of a top-level error's chain, other than the first.
+func (tracer *Tracer) startsGroup() bool {
+	return tracer.readCount > 0 && len(tracer.errorChain) > 0 && len(tracer.errorChain) == tracer.chainLength
+}
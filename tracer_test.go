@@ -18,11 +18,15 @@ package xtrace
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/xerrors"
@@ -146,6 +150,26 @@ func TestTracer_ReadNext(t *testing.T) {
 				assert.Equal(t, io.EOF, err)
 			},
 		},
+		{
+			name: "self-wrapping error",
+			setup: func(t *testing.T) *Tracer {
+				err := &selfWrappingError{message: "things broke :("}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for _, expected := range []string{"things broke :(", "aw shucks"} {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
 		{
 			name: "empty error",
 			setup: func(t *testing.T) *Tracer {
@@ -160,6 +184,119 @@ func TestTracer_ReadNext(t *testing.T) {
 				assert.Nil(t, err)
 			},
 		},
+		{
+			name: "empty error, localized placeholder",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("")
+				tracer, constructErr := NewTracer(err, WithMessages(Messages{Empty: "(vacío)"}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Equal(t, "(vacío)", message)
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name: "detailed output with no detail",
+			setup: func(t *testing.T) *Tracer {
+				err := emptyDetailError{message: "things broke :("}
+				tracer, constructErr := NewTracer(err, DetailedOutput(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+			},
+		},
+		{
+			name: "detail is only checked for once",
+			setup: func(t *testing.T) *Tracer {
+				err := &countingDetailError{message: "things broke :("}
+				tracer, constructErr := NewTracer(err, DetailedOutput(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+
+				// The detailed output is rendered once, and the same output is both checked for content and formatted.
+				assert.Equal(t, 1, tracer.baseErr.(*countingDetailError).calls)
+			},
+		},
+		{
+			name: "unsynchronized",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Unsynchronized(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for _, expected := range []string{"things broke :(", "aw shucks"} {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "show hash",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, ShowHash(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Regexp(t, `^\[[0-9a-f]{8}\] things broke :\($`, message)
+
+				message, err = tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Regexp(t, `^\[[0-9a-f]{8}\] aw shucks\n`, message)
+
+				// Detailed output must not affect the hash
+				plainErr := xerrors.Errorf("aw shucks: %w", errors.New(""))
+				plainTracer, err := NewTracer(plainErr, ShowHash(true), DetailedOutput(false))
+				assert.Nil(t, err)
+				_, err = plainTracer.ReadNext()
+				assert.Nil(t, err)
+				plainMessage, err := plainTracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, plainMessage[:10], message[:10])
+			},
+		},
+		{
+			name: "detail separator",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err, DetailSeparator("\n"), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Regexp(t, "^aw shucks\n\n\\S", message)
+
+				// Errors without detailed output are left as is
+				message, err = tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+			},
+		},
 		{
 			name: "reset Read",
 			setup: func(t *testing.T) *Tracer {
@@ -366,6 +503,61 @@ func TestTracer_Read(t *testing.T) {
 				assert.Equal(t, string(fullBufferClone), string(fullBuffer))
 			},
 		},
+		{
+			name: "custom buffer",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(err, Buffer(bytes.NewBufferString("leftover contents")))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := make([]byte, len("things broke :("))
+				n, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, len(buffer), n)
+				assert.Equal(t, "things broke :(", string(buffer))
+			},
+		},
+		{
+			name: "nil buffer",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(err, Buffer(nil))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := make([]byte, len("things broke :("))
+				n, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, len(buffer), n)
+				assert.Equal(t, "things broke :(", string(buffer))
+			},
+		},
+		{
+			name: "custom buffer not reset by Format",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(err, Buffer(bytes.NewBufferString("")))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := make([]byte, 5)
+				_, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "thing", string(buffer))
+
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, "things broke :(", out)
+
+				n, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, 5, n)
+				assert.Equal(t, "s bro", string(buffer))
+			},
+		},
 		{
 			name: "many errors, test error boundary",
 			setup: func(t *testing.T) *Tracer {
@@ -446,6 +638,44 @@ func TestTracer_Read(t *testing.T) {
 	runTracerTestTable(t, tests)
 }
 
+func TestTracer_Format(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "go syntax",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%#v", tracer)
+				assert.Equal(t, `&xtrace.Tracer{errors:[]string{"things broke :(", "aw shucks"}}`, out)
+
+				// The Tracer should not have been read from.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, 1, strings.Count(message, "things broke :("))
+			},
+		},
+		{
+			name: "go syntax, no errors",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%#v", tracer)
+				assert.Equal(t, `&xtrace.Tracer{errors:[]string{}}`, out)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
 func TestTracer_Trace(t *testing.T) {
 	tests := []tracerTest{
 		{
@@ -487,49 +717,3038 @@ func TestTracer_Trace(t *testing.T) {
 				}())
 			},
 		},
-	}
-
-	runTracerTestTable(t, tests)
-}
+		{
+			name: "shared formatter is reset",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				formatter, constructErr := NewGlobalDedupeFormatter()
+				if constructErr != nil {
+					return handleTracerTestSetupError(t, nil, constructErr)
+				}
 
-type capsFormatter struct{}
+				tracer, constructErr := NewTracer(err, Formatter(formatter))
 
-func (formatter capsFormatter) FormatTrace(previous []string, message string) string {
-	return strings.ToUpper(message)
-}
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for i := 0; i < 2; i++ {
+					buffer := bytes.NewBufferString("")
+					err := tracer.Trace(buffer)
+					assert.Nil(t, err)
+					assert.Equal(t, "things broke :(", buffer.String())
+				}
+			},
+		},
+		{
+			name: "line prefix",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				calls := 0
+				prefixFunc := func() string {
+					calls++
+					return fmt.Sprintf("[req-%d] ", calls)
+				}
+				tracer, constructErr := NewTracer(err3, LinePrefix(prefixFunc))
 
-func ExampleNewTracer() {
-	baseErr := errors.New("aw shucks, something broke")
-	// capsFormatter is a custom formatter that simply applies strings.ToUpper to all messages
-	tracer, err := NewTracer(baseErr, Formatter(capsFormatter{}))
-	if err != nil {
-		panic("can not make tracer")
-	}
-	output, err := tracer.ReadNext()
-	if err != nil {
-		panic("can not read from tracer")
-	}
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
 
-	fmt.Println(output)
-	// Output: AW SHUCKS, SOMETHING BROKE
-}
+				lines := strings.Split(buffer.String(), "\n")
+				assert.True(t, len(lines) > 3, buffer.String())
+				for _, line := range lines {
+					assert.True(t, strings.HasPrefix(line, "[req-1] "), line)
+				}
+			},
+		},
+		{
+			name: "line prefix, trailing newline",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				prefixFunc := func() string {
+					return "> "
+				}
+				tracer, constructErr := NewTracer(
+					err2,
+					DetailedOutput(false),
+					KeepTrailing(true),
+					LinePrefix(prefixFunc),
+				)
 
-func ExampleTracer_Format() {
-	baseErr := errors.New("aw shucks, something broke")
-	err2 := xerrors.Errorf("things went wrong!: %w", baseErr)
-	tracer, err := NewTracer(err2)
-	if err != nil {
-		panic("can not make tracer")
-	}
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "> things broke :(\n> aw shucks\n", buffer.String())
+			},
+		},
+		{
+			name: "strip common prefix",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("myservice: things broke :(")
+				err2 := xerrors.Errorf("myservice: aw shucks: %w", err)
+				err3 := xerrors.Errorf("myservice: I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), StripCommonPrefix(true))
 
-	fmt.Printf("%v", tracer)
-	// Output: aw shucks, something broke
-	// things went wrong!
-}
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "myservice:\nthings broke :(\naw shucks\nI tried very hard and failed", buffer.String())
+			},
+		},
+		{
+			name: "strip common prefix, partial word",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("myservice: aw snap")
+				err2 := xerrors.Errorf("myservice: aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), StripCommonPrefix(true))
 
-func ExampleOrdering() {
-	baseErr := errors.New("aw shucks, something broke")
-	err2 := xerrors.Errorf("things went wrong!: %w", baseErr)
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "myservice:\naw snap\naw shucks", buffer.String())
+			},
+		},
+		{
+			name: "strip common prefix, no shared prefix",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("myservice: things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), StripCommonPrefix(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "myservice: things broke :(\naw shucks", buffer.String())
+			},
+		},
+		{
+			name: "fields",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(
+					err2,
+					DetailedOutput(false),
+					WithField("user", "nick k"),
+					WithField("request_id", "abc123"),
+					WithField("attempt", 2),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				expected := "attempt=2 request_id=abc123 user=\"nick k\"\nthings broke :(\naw shucks"
+				assert.Equal(t, expected, buffer.String())
+
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+			},
+		},
+		{
+			name: "fields, YAML",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(
+					err,
+					DetailedOutput(false),
+					Formatter(NewYAMLFormatter()),
+					WithField("request_id", "abc123"),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.True(t, strings.HasPrefix(buffer.String(), "# request_id=abc123\n- depth: 0\n"), buffer.String())
+			},
+		},
+		{
+			name: "max bytes",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), MaxBytes(20))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\naw s... (truncated)", buffer.String())
+			},
+		},
+		{
+			name: "max bytes, utf-8 boundary",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke ☹")
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), MaxBytes(14))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke ... (truncated)", buffer.String())
+			},
+		},
+		{
+			name: "max bytes, localized marker",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(
+					err,
+					DetailedOutput(false),
+					MaxBytes(6),
+					WithMessages(Messages{Truncated: "... (truncado)"}),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things... (truncado)", buffer.String())
+			},
+		},
+		{
+			name: "max lines",
+			setup: func(t *testing.T) *Tracer {
+				err := frameTestError{message: "things broke :(", function: "main.main", file: "/home/nick/main.go"}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailDepth(1), MaxLines(2))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\nmain.main\n... (truncated)", buffer.String())
+			},
+		},
+		{
+			name: "max lines, exact",
+			setup: func(t *testing.T) *Tracer {
+				err := frameTestError{message: "things broke :(", function: "main.main", file: "/home/nick/main.go"}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailDepth(1), MaxLines(4))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\nmain.main\n    /home/nick/main.go:12\naw shucks", buffer.String())
+			},
+		},
+		{
+			name: "max lines, with max bytes",
+			setup: func(t *testing.T) *Tracer {
+				err := frameTestError{message: "things broke :(", function: "main.main", file: "/home/nick/main.go"}
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailDepth(1), MaxLines(3), MaxBytes(20))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\nmain... (truncated)", buffer.String())
+			},
+		},
+		{
+			name: "detail depth",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(true), DetailDepth(1))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+
+				// Only the root cause's frame should be present.
+				bufferString := buffer.String()
+				assert.Equal(t, 1, strings.Count(bufferString, "tracer_test.go"), bufferString)
+				assert.True(t, strings.HasSuffix(bufferString, "aw shucks\nI tried very hard and failed"), bufferString)
+			},
+		},
+		{
+			name: "keep trailing",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), KeepTrailing(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				for i := 0; i < 2; i++ {
+					err := tracer.Trace(buffer)
+					assert.Nil(t, err)
+				}
+
+				assert.Equal(t, "things broke :(\naw shucks\nthings broke :(\naw shucks\n", buffer.String())
+			},
+		},
+		{
+			name: "keep trailing, group separator",
+			setup: func(t *testing.T) *Tracer {
+				errs := []error{
+					xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")),
+					errors.New("an awful thing happened"),
+				}
+				tracer, constructErr := NewMultiTracer(
+					errs,
+					DetailedOutput(false),
+					KeepTrailing(true),
+					GroupSeparator("--\n"),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\naw shucks\n--\nan awful thing happened\n", buffer.String())
+			},
+		},
+		{
+			name: "keep trailing, raw block",
+			setup: func(t *testing.T) *Tracer {
+				err := fmt.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), KeepTrailing(true), RawBlock(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\naw shucks: things broke :(\n", buffer.String())
+			},
+		},
+		{
+			name: "keep trailing, no errors",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil, KeepTrailing(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "", buffer.String())
+			},
+		},
+		{
+			name: "suppress detail for type",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(
+					err3,
+					DetailedOutput(true),
+					SuppressDetailFor(xerrors.New(""), xerrors.Errorf(": %w", errors.New(""))),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+
+				// Only the error produced by Errorf should have its frame, as the xerrors errors are suppressed.
+				bufferString := buffer.String()
+				assert.Equal(t, 1, strings.Count(bufferString, "tracer_test.go"), bufferString)
+				expectedPrefix := "things broke :(\naw shucks\nI tried very hard and failed\n"
+				assert.True(t, strings.HasPrefix(bufferString, expectedPrefix), bufferString)
+			},
+		},
+		{
+			name: "raw block",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := fmt.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(
+					err2,
+					DetailedOutput(false),
+					RawBlock(true),
+					Formatter(capsFormatter{}),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\naw shucks: things broke :(", buffer.String())
+			},
+		},
+		{
+			name: "raw block, detailed output",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(true), RawBlock(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+
+				baseErr := tracer.BaseError()
+				expected := fmt.Sprintf("%+v\n%+v", xerrors.Unwrap(baseErr), baseErr)
+				assert.Equal(t, expected, buffer.String())
+			},
+		},
+		{
+			name: "raw block, multiple errors",
+			setup: func(t *testing.T) *Tracer {
+				errs := []error{
+					fmt.Errorf("aw shucks: %w", errors.New("things broke :(")),
+					errors.New("an awful thing happened"),
+				}
+				tracer, constructErr := NewMultiTracer(
+					errs,
+					DetailedOutput(false),
+					RawBlock(true),
+					GroupSeparator("--\n"),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				expected := "things broke :(\naw shucks: things broke :(\n--\nan awful thing happened"
+				assert.Equal(t, expected, buffer.String())
+			},
+		},
+		{
+			name: "max bytes, not exceeded",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), MaxBytes(15))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.Trace(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", buffer.String())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+type tracerTestError struct {
+	message string
+}
+
+func (err tracerTestError) Error() string {
+	return err.message
+}
+
+// emptyDetailError is an xerrors.Formatter that produces nothing but whitespace when detailed output is requested.
+type emptyDetailError struct {
+	message string
+}
+
+func (err emptyDetailError) Error() string {
+	return err.message
+}
+
+func (err emptyDetailError) FormatError(printer xerrors.Printer) error {
+	printer.Print(err.message)
+	if printer.Detail() {
+		printer.Print("\n\n")
+	}
+
+	return nil
+}
+
+// countingDetailError is an xerrors.Formatter that counts the number of times FormatError is called, and produces
+// nothing but whitespace when detailed output is requested.
+type countingDetailError struct {
+	message string
+	calls   int
+}
+
+func (err *countingDetailError) Error() string {
+	return err.message
+}
+
+func (err *countingDetailError) FormatError(printer xerrors.Printer) error {
+	err.calls++
+	printer.Print(err.message)
+	if printer.Detail() {
+		printer.Print("\n")
+	}
+
+	return nil
+}
+
+// indentedDetailError is an xerrors.Formatter that produces all of its detailed output, with indented frame lines, as a
+// single message.
+type indentedDetailError struct {
+	message string
+}
+
+func (err indentedDetailError) Error() string {
+	return err.message
+}
+
+func (err indentedDetailError) FormatError(printer xerrors.Printer) error {
+	printer.Print(err.message)
+	if printer.Detail() {
+		printer.Print("    main.main\n    /home/nick/main.go:12\n")
+	}
+
+	return nil
+}
+
+// frameTestError is an xerrors.Formatter that writes the given function and file as its frame in its detailed output,
+// in the same way that xerrors does.
+type frameTestError struct {
+	message  string
+	function string
+	file     string
+}
+
+func (err frameTestError) Error() string {
+	return err.message
+}
+
+func (err frameTestError) FormatError(printer xerrors.Printer) error {
+	printer.Print(err.message)
+	if printer.Detail() {
+		printer.Printf("%s\n    ", err.function)
+		printer.Printf("%s:%d\n", err.file, 12)
+	}
+
+	return nil
+}
+
+// verboseError is an error that does not implement xerrors.Formatter, but prints more than its message with %+v.
+type verboseError struct {
+	message string
+}
+
+func (err verboseError) Error() string {
+	return err.message
+}
+
+func (err verboseError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, err.message)
+	if s.Flag('+') {
+		io.WriteString(s, "\nmain.main\n\t/home/nick/main.go:12")
+	}
+}
+
+// selfWrappingError is an error that unwraps to itself.
+type selfWrappingError struct {
+	message string
+}
+
+func (err *selfWrappingError) Error() string {
+	return err.message
+}
+
+func (err *selfWrappingError) Unwrap() error {
+	return err
+}
+
+// timestampedError is an error that carries a timestamp.
+type timestampedError struct {
+	message   string
+	timestamp time.Time
+	wrapped   error
+}
+
+func (err timestampedError) Error() string {
+	return err.message
+}
+
+func (err timestampedError) Timestamp() time.Time {
+	return err.timestamp
+}
+
+func (err timestampedError) Unwrap() error {
+	return err.wrapped
+}
+
+// whenError is an error that records when it occurred.
+type whenError struct {
+	message string
+	when    time.Time
+	wrapped error
+}
+
+func (err whenError) Error() string {
+	return err.message
+}
+
+func (err whenError) When() time.Time {
+	return err.when
+}
+
+func (err whenError) Unwrap() error {
+	return err.wrapped
+}
+
+func TestTracer_From(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "matching error",
+			setup: func(t *testing.T) *Tracer {
+				err := tracerTestError{message: "aw shucks"}
+				err2 := xerrors.Errorf("I tried very hard and failed: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				var target tracerTestError
+				subTracer, err := tracer.From(&target)
+				assert.Nil(t, err)
+				assert.Equal(t, "aw shucks", target.message)
+
+				message, err := subTracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "aw shucks", message)
+
+				_, err = subTracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "matching error, newest first ordering",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				var target xerrors.Wrapper
+				subTracer, err := tracer.From(&target)
+				assert.Nil(t, err)
+
+				out := fmt.Sprintf("%v", subTracer)
+				assert.Equal(t, "I tried very hard and failed\naw shucks\nthings broke :(", out)
+			},
+		},
+		{
+			name: "no matching error",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				var target tracerTestError
+				subTracer, err := tracer.From(&target)
+				assert.NotNil(t, err)
+				assert.Nil(t, subTracer)
+			},
+		},
+		{
+			name: "invalid target",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(errors.New("things broke :("))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.From(nil)
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+// depthFormatter prefixes every message with the depth and index of the error it belongs to.
+type depthFormatter struct{}
+
+func (formatter depthFormatter) FormatTrace(previous []string, message string) string {
+	return message
+}
+
+func (formatter depthFormatter) FormatTraceWithContext(context TraceContext, previous []string, message string) string {
+	return fmt.Sprintf("%d/%d: %s", context.Depth, context.Index, message)
+}
+
+func TestStableOrderingFunc(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "ties kept in chain order",
+			setup: func(t *testing.T) *Tracer {
+				err := tracerTestError{message: "aw shucks"}
+				err2 := xerrors.Errorf("retrying: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				key := func(err error) int {
+					if _, isTestError := err.(tracerTestError); isTestError {
+						return 1
+					}
+
+					return 0
+				}
+				tracer, constructErr := NewTracer(
+					err3,
+					DetailedOutput(false),
+					StableOrderingFunc(key),
+					Formatter(depthFormatter{}),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expectedMessages := []string{
+					"1/0: retrying",
+					"2/1: I tried very hard and failed",
+					"0/2: aw shucks",
+				}
+				for _, expected := range expectedMessages {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+
+	key := func(error) int { return 0 }
+	_, err := NewTracer(errors.New("things broke :("), Ordering(NewestFirstOrdering), StableOrderingFunc(key))
+	assert.NotNil(t, err)
+
+	_, err = NewTracer(errors.New("things broke :("), StableOrderingFunc(key), Ordering(OldestFirstOrdering))
+	assert.NotNil(t, err)
+}
+
+// dropFormatter drops any error whose message contains "aw shucks".
+type dropFormatter struct{}
+
+func (formatter dropFormatter) FormatTrace(previous []string, message string) string {
+	if strings.Contains(message, "aw shucks") {
+		return Dropped
+	}
+
+	return message
+}
+
+// causeUnwrapError is an error whose Cause and Unwrap methods return different errors.
+type causeUnwrapError struct {
+	message string
+	cause   error
+	wrapped error
+}
+
+func (err causeUnwrapError) Error() string {
+	return err.message
+}
+
+func (err causeUnwrapError) Cause() error {
+	return err.cause
+}
+
+func (err causeUnwrapError) Unwrap() error {
+	return err.wrapped
+}
+
+func TestPreferCause(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferCause bool
+		expected    string
+	}{
+		{
+			name:        "unwrap",
+			preferCause: false,
+			expected:    "an awful thing happened\naw shucks",
+		},
+		{
+			name:        "prefer cause",
+			preferCause: true,
+			expected:    "things broke :(\nI tried very hard and failed\naw shucks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Errors without a Cause method must still be unwrapped.
+			cause := xerrors.Errorf("I tried very hard and failed: %w", errors.New("things broke :("))
+			err := causeUnwrapError{
+				message: "aw shucks",
+				cause:   cause,
+				wrapped: errors.New("an awful thing happened"),
+			}
+
+			tracer, constructErr := NewTracer(err, DetailedOutput(false), PreferCause(tt.preferCause))
+			assert.Nil(t, constructErr)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%v", tracer))
+		})
+	}
+}
+
+func TestStopAt(t *testing.T) {
+	// timestampedError stands in for the error of a framework, which wraps the root cause.
+	isFramework := func(err error) bool {
+		_, isFrameworkErr := err.(timestampedError)
+
+		return isFrameworkErr
+	}
+
+	tests := []struct {
+		name      string
+		predicate func(error) bool
+		inclusive bool
+		ordering  TraceOrderingMethod
+		expected  string
+	}{
+		{
+			name:      "inclusive",
+			predicate: isFramework,
+			inclusive: true,
+			ordering:  OldestFirstOrdering,
+			expected:  "aw shucks\nretrying\nI tried very hard and failed",
+		},
+		{
+			name:      "exclusive",
+			predicate: isFramework,
+			inclusive: false,
+			ordering:  OldestFirstOrdering,
+			expected:  "retrying\nI tried very hard and failed",
+		},
+		{
+			name:      "inclusive, newest first",
+			predicate: isFramework,
+			inclusive: true,
+			ordering:  NewestFirstOrdering,
+			expected:  "I tried very hard and failed\nretrying\naw shucks",
+		},
+		{
+			name:      "exclusive, newest first",
+			predicate: isFramework,
+			inclusive: false,
+			ordering:  NewestFirstOrdering,
+			expected:  "I tried very hard and failed\nretrying",
+		},
+		{
+			name:      "no match",
+			predicate: func(err error) bool { return false },
+			inclusive: true,
+			ordering:  OldestFirstOrdering,
+			expected:  "things broke :(\naw shucks\nretrying\nI tried very hard and failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frameworkErr := timestampedError{message: "aw shucks", wrapped: errors.New("things broke :(")}
+			err := xerrors.Errorf("I tried very hard and failed: %w", xerrors.Errorf("retrying: %w", frameworkErr))
+			tracer, constructErr := NewTracer(
+				err,
+				DetailedOutput(false),
+				StopAt(tt.predicate, tt.inclusive),
+				Ordering(tt.ordering),
+			)
+			assert.Nil(t, constructErr)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%v", tracer))
+		})
+	}
+}
+
+func TestStopAt_NilPredicate(t *testing.T) {
+	_, err := NewTracer(errors.New("things broke :("), StopAt(nil, true))
+	assert.NotNil(t, err)
+}
+
+// fieldsFormatter replaces every message with the fields of the trace.
+type fieldsFormatter struct{}
+
+func (formatter fieldsFormatter) FormatTrace(previous []string, message string) string {
+	return message
+}
+
+func (formatter fieldsFormatter) FormatTraceWithContext(
+	context TraceContext,
+	previous []string,
+	message string,
+) string {
+	return fmt.Sprint(context.Fields)
+}
+
+func TestWithField(t *testing.T) {
+	tracer, err := NewTracer(
+		errors.New("things broke :("),
+		Formatter(fieldsFormatter{}),
+		WithField("request_id", "abc123"),
+	)
+	assert.Nil(t, err)
+
+	message, err := tracer.ReadNext()
+	assert.Nil(t, err)
+	assert.Equal(t, "map[request_id:abc123]", message)
+
+	_, err = NewTracer(errors.New("things broke :("), WithField("", "abc123"))
+	assert.NotNil(t, err)
+}
+
+func TestTracer_DroppedErrors(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "ReadNext",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), Formatter(dropFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for _, expected := range []string{"things broke :(", "I tried very hard and failed"} {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "Read",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(
+					err,
+					DetailedOutput(false),
+					Ordering(NewestFirstOrdering),
+					Formatter(dropFormatter{}),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := make([]byte, len("things broke :("))
+				n, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, len(buffer), n)
+				assert.Equal(t, "things broke :(", string(buffer))
+
+				_, err = tracer.Read(buffer)
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "all errors dropped",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("aw shucks")
+				tracer, constructErr := NewTracer(err, Formatter(dropFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, "", out)
+
+				_, err := tracer.Root()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_ContextualFormatter(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "oldest first ordering",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), Formatter(depthFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expectedMessages := []string{
+					"0/0: things broke :(",
+					"1/1: aw shucks",
+					"2/2: I tried very hard and failed",
+				}
+				for _, expected := range expectedMessages {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+			},
+		},
+		{
+			name: "newest first ordering",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(
+					err3,
+					DetailedOutput(false),
+					Formatter(depthFormatter{}),
+					Ordering(NewestFirstOrdering),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expectedMessages := []string{
+					"2/0: I tried very hard and failed",
+					"1/1: aw shucks",
+					"0/2: things broke :(",
+				}
+				for _, expected := range expectedMessages {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func BenchmarkTracer_Read(b *testing.B) {
+	err := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err)
+	readBuffer := make([]byte, 64)
+	b.Run("default buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tracer, _ := NewTracer(err2)
+			for _, readErr := tracer.Read(readBuffer); readErr != io.EOF; _, readErr = tracer.Read(readBuffer) {
+			}
+		}
+	})
+
+	b.Run("reused buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		tracerBuffer := bytes.NewBuffer(make([]byte, 0, 1024))
+		for i := 0; i < b.N; i++ {
+			tracer, _ := NewTracer(err2, Buffer(tracerBuffer))
+			for _, readErr := tracer.Read(readBuffer); readErr != io.EOF; _, readErr = tracer.Read(readBuffer) {
+			}
+		}
+	})
+}
+
+// joinedError is an error that wraps several errors, in the same way as the errors produced by errors.Join.
+type joinedError []error
+
+func (err joinedError) Error() string {
+	messages := []string{}
+	for _, wrapped := range err {
+		messages = append(messages, wrapped.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (err joinedError) Unwrap() []error {
+	return err
+}
+
+func TestNewMultiTracer(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "independent errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := errors.New("an awful thing happened")
+				tracer, constructErr := NewMultiTracer([]error{err2, nil, err3}, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, "things broke :(\naw shucks\nan awful thing happened", out)
+			},
+		},
+		{
+			name: "independent errors, newest first ordering",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := errors.New("an awful thing happened")
+				err4 := xerrors.Errorf("I tried very hard and failed: %w", err3)
+				tracer, constructErr := NewMultiTracer(
+					[]error{err2, err4},
+					DetailedOutput(false),
+					Ordering(NewestFirstOrdering),
+					Formatter(depthFormatter{}),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expectedMessages := []string{
+					"1/0: aw shucks",
+					"0/1: things broke :(",
+					"1/2: I tried very hard and failed",
+					"0/3: an awful thing happened",
+				}
+				for _, expected := range expectedMessages {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "group separator",
+			setup: func(t *testing.T) *Tracer {
+				errs := []error{
+					xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")),
+					xerrors.Errorf("I tried very hard and failed: %w", errors.New("an awful thing happened")),
+					xerrors.Errorf("retrying: %w", errors.New("connection refused")),
+				}
+				tracer, constructErr := NewMultiTracer(errs, DetailedOutput(false), GroupSeparator("\n"))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expected := "things broke :(\naw shucks\n\n" +
+					"an awful thing happened\nI tried very hard and failed\n\n" +
+					"connection refused\nretrying"
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, expected, out)
+			},
+		},
+		{
+			name: "sorted branches of a joined error",
+			setup: func(t *testing.T) *Tracer {
+				joined := joinedError{
+					xerrors.Errorf("retrying: %w", errors.New("connection refused")),
+					xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")),
+					xerrors.Errorf("I tried very hard and failed: %w", errors.New("an awful thing happened")),
+				}
+				tracer, constructErr := NewMultiTracer(joined.Unwrap(), DetailedOutput(false), SortBranches(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expected := "an awful thing happened\nI tried very hard and failed\n" +
+					"things broke :(\naw shucks\n" +
+					"connection refused\nretrying"
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, expected, out)
+			},
+		},
+		{
+			name: "unsorted branches of a joined error",
+			setup: func(t *testing.T) *Tracer {
+				joined := joinedError{
+					errors.New("things broke :("),
+					errors.New("connection refused"),
+					errors.New("an awful thing happened"),
+				}
+				tracer, constructErr := NewMultiTracer(joined.Unwrap(), DetailedOutput(false), SortBranches(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out := fmt.Sprintf("%v", tracer)
+				assert.Equal(t, "things broke :(\nconnection refused\nan awful thing happened", out)
+			},
+		},
+		{
+			name: "no errors",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewMultiTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+// isError is an error that matches a target error through its Is method.
+type isError struct {
+	message string
+	target  error
+}
+
+func (err isError) Error() string {
+	return err.message
+}
+
+func (err isError) Is(target error) bool {
+	return target == err.target
+}
+
+func TestTracer_SinceBase(t *testing.T) {
+	baseErr := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		err      error
+		base     error
+		options  []func(*Tracer) error
+		expected string
+	}{
+		{
+			name:     "oldest first",
+			err:      xerrors.Errorf("giving up: %w", xerrors.Errorf("attempt 2: %w", baseErr)),
+			base:     baseErr,
+			options:  nil,
+			expected: "attempt 2\ngiving up",
+		},
+		{
+			name:     "newest first",
+			err:      xerrors.Errorf("giving up: %w", xerrors.Errorf("attempt 2: %w", baseErr)),
+			base:     baseErr,
+			options:  []func(*Tracer) error{Ordering(NewestFirstOrdering)},
+			expected: "giving up\nattempt 2",
+		},
+		{
+			name: "matched by Is",
+			err: xerrors.Errorf(
+				"giving up: %w",
+				xerrors.Errorf("attempt 2: %w", isError{message: "timed out", target: baseErr}),
+			),
+			base:     baseErr,
+			options:  nil,
+			expected: "attempt 2\ngiving up",
+		},
+		{
+			name:     "base is the most recent error",
+			err:      baseErr,
+			base:     baseErr,
+			options:  nil,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]func(*Tracer) error{DetailedOutput(false)}, tt.options...)
+			tracer, err := NewTracer(tt.err, options...)
+			assert.Nil(t, err)
+
+			sinceTracer, err := tracer.SinceBase(tt.base)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%v", sinceTracer))
+		})
+	}
+}
+
+func TestTracer_SinceBase_NotFound(t *testing.T) {
+	tracer, err := NewTracer(xerrors.Errorf("aw shucks: %w", errors.New("things broke :(")))
+	assert.Nil(t, err)
+
+	_, err = tracer.SinceBase(errors.New("connection refused"))
+	assert.NotNil(t, err)
+
+	_, err = tracer.SinceBase(nil)
+	assert.NotNil(t, err)
+}
+
+func TestTracer_Concat(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "two tracers",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				cleanupErr := xerrors.Errorf("an awful thing happened: %w", errors.New("could not clean up"))
+				other, err := NewTracer(cleanupErr)
+				assert.Nil(t, err)
+
+				_, err = tracer.ReadNext()
+				assert.Nil(t, err)
+
+				concatenated, err := tracer.Concat(other)
+				assert.Nil(t, err)
+				out := fmt.Sprintf("%v", concatenated)
+				assert.Equal(t, "aw shucks\nthings broke :(\nan awful thing happened\ncould not clean up", out)
+			},
+		},
+		{
+			name: "nil tracer",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(errors.New("things broke :("))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.Concat(nil)
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_BaseError(t *testing.T) {
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, err := NewTracer(baseErr)
+	assert.Nil(t, err)
+
+	_, err = tracer.ReadNext()
+	assert.Nil(t, err)
+	assert.Equal(t, baseErr, tracer.BaseError())
+}
+
+func TestTracer_Ordering(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []func(*Tracer) error
+		expected TraceOrderingMethod
+	}{
+		{
+			name:     "default",
+			options:  nil,
+			expected: OldestFirstOrdering,
+		},
+		{
+			name:     "newest first",
+			options:  []func(*Tracer) error{Ordering(NewestFirstOrdering)},
+			expected: NewestFirstOrdering,
+		},
+		{
+			name:     "oldest first",
+			options:  []func(*Tracer) error{Ordering(OldestFirstOrdering)},
+			expected: OldestFirstOrdering,
+		},
+		{
+			name:     "read ordering overrides ordering",
+			options:  []func(*Tracer) error{ReadOrdering(NewestFirstOrdering), Ordering(OldestFirstOrdering)},
+			expected: NewestFirstOrdering,
+		},
+		{
+			name:     "trace ordering does not affect reads",
+			options:  []func(*Tracer) error{TraceOrdering(NewestFirstOrdering)},
+			expected: OldestFirstOrdering,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewTracer(errors.New("things broke :("), tt.options...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, tracer.Ordering())
+		})
+	}
+}
+
+func TestReadAndTraceOrdering(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []func(*Tracer) error
+		expectedRead  string
+		expectedTrace string
+	}{
+		{
+			name:          "ordering only",
+			options:       []func(*Tracer) error{Ordering(NewestFirstOrdering)},
+			expectedRead:  "aw shucks",
+			expectedTrace: "aw shucks\nthings broke :(",
+		},
+		{
+			name:          "read newest first, trace oldest first",
+			options:       []func(*Tracer) error{ReadOrdering(NewestFirstOrdering), TraceOrdering(OldestFirstOrdering)},
+			expectedRead:  "aw shucks",
+			expectedTrace: "things broke :(\naw shucks",
+		},
+		{
+			name:          "trace ordering overrides ordering",
+			options:       []func(*Tracer) error{Ordering(OldestFirstOrdering), TraceOrdering(NewestFirstOrdering)},
+			expectedRead:  "things broke :(",
+			expectedTrace: "aw shucks\nthings broke :(",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+			options := append([]func(*Tracer) error{DetailedOutput(false)}, tt.options...)
+			tracer, constructErr := NewTracer(err, options...)
+			assert.Nil(t, constructErr)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expectedTrace, output.String())
+			assert.Equal(t, tt.expectedTrace, fmt.Sprintf("%v", tracer))
+
+			message, readErr := tracer.ReadNext()
+			assert.Nil(t, readErr)
+			assert.Equal(t, tt.expectedRead, message)
+		})
+	}
+}
+
+func TestReadAndTraceOrdering_StableOrderingFunc(t *testing.T) {
+	key := func(err error) int { return 0 }
+	_, err := NewTracer(errors.New("things broke :("), ReadOrdering(NewestFirstOrdering), StableOrderingFunc(key))
+	assert.NotNil(t, err)
+
+	_, err = NewTracer(errors.New("things broke :("), StableOrderingFunc(key), TraceOrdering(NewestFirstOrdering))
+	assert.NotNil(t, err)
+}
+
+func TestTracer_WriteFramed(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(\nreally")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(NewNilFormatter()))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.WriteFramed(buffer)
+				assert.Nil(t, err)
+
+				expected := "\x00\x00\x00\x16things broke :(\nreally" + "\x00\x00\x00\x09aw shucks"
+				assert.Equal(t, expected, buffer.String())
+				assert.True(t, tracer.Exhausted())
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.WriteFramed(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, 0, buffer.Len())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_EncodeJSON(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(\nreally")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(NewNilFormatter()))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				encoder := json.NewEncoder(buffer)
+				assert.Nil(t, encoder.Encode("before"))
+				assert.Nil(t, tracer.EncodeJSON(encoder))
+				assert.Nil(t, encoder.Encode("after"))
+
+				expected := "\"before\"\n" + `["things broke :(\nreally","aw shucks"]` + "\n\"after\"\n"
+				assert.Equal(t, expected, buffer.String())
+				assert.True(t, tracer.Exhausted())
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.EncodeJSON(json.NewEncoder(buffer))
+				assert.Nil(t, err)
+				assert.Equal(t, "[]\n", buffer.String())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Chan(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("an awful thing happened: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				messages := []string{}
+				for message := range tracer.Chan() {
+					messages = append(messages, message)
+				}
+
+				assert.Equal(t, []string{"things broke :(", "aw shucks", "an awful thing happened"}, messages)
+				assert.True(t, tracer.Exhausted())
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, open := <-tracer.Chan()
+				assert.False(t, open)
+			},
+		},
+		{
+			name: "cancelled context",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				ctx, cancel := context.WithCancel(context.Background())
+				messages := tracer.ChanContext(ctx)
+				assert.Equal(t, "things broke :(", <-messages)
+
+				cancel()
+				// The channel must be closed once the context is done, even though a message was never received.
+				for range messages {
+				}
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Root(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(capsFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				root, err := tracer.Root()
+				assert.Nil(t, err)
+				assert.Equal(t, "THINGS BROKE :(", root)
+
+				// The Tracer should not have been read from.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "THINGS BROKE :(", message)
+			},
+		},
+		{
+			name: "stateful formatter",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				formatter := NewMarkdownOrderedFormatter()
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(formatter))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				root, err := tracer.Root()
+				assert.Nil(t, err)
+				assert.Equal(t, "1. things broke", root)
+
+				// The formatter should not have been advanced by Root.
+				for _, expected := range []string{"1. things broke", "2. aw shucks"} {
+					message, err := tracer.ReadNext()
+					assert.Nil(t, err)
+					assert.Equal(t, expected, message)
+				}
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.Root()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Top(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(capsFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				top, err := tracer.Top()
+				assert.Nil(t, err)
+				assert.Equal(t, "AW SHUCKS", top)
+
+				// The Tracer should not have been read from.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "THINGS BROKE :(", message)
+			},
+		},
+		{
+			name: "newest first",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				top, err := tracer.Top()
+				assert.Nil(t, err)
+				assert.Equal(t, "aw shucks", top)
+			},
+		},
+		{
+			name: "stateful formatter",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				formatter := NewMarkdownOrderedFormatter()
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(formatter))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				// Reading part of the trace first makes sure that Top does not disturb a trace in progress, either.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "1. things broke", message)
+
+				// Top is formatted on its own, so it is numbered as if it were the only item.
+				top, err := tracer.Top()
+				assert.Nil(t, err)
+				assert.Equal(t, "1. aw shucks", top)
+
+				message, err = tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "2. aw shucks", message)
+
+				_, err = tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "multiple top-level errors",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				errs := []error{nil, err, errors.New("oh no")}
+				tracer, constructErr := NewMultiTracer(errs, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				top, err := tracer.Top()
+				assert.Nil(t, err)
+				assert.Equal(t, "aw shucks", top)
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.Top()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Walk(t *testing.T) {
+	type walkedError struct {
+		depth int
+		err   error
+	}
+
+	err := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err)
+	err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+	err4 := errors.New("an awful thing happened")
+	tests := []struct {
+		name     string
+		options  []func(*Tracer) error
+		limit    int
+		expected []walkedError
+	}{
+		{
+			name:     "oldest first",
+			options:  nil,
+			limit:    -1,
+			expected: []walkedError{{0, err}, {1, err2}, {2, err3}, {0, err4}},
+		},
+		{
+			name:     "newest first",
+			options:  []func(*Tracer) error{Ordering(NewestFirstOrdering)},
+			limit:    -1,
+			expected: []walkedError{{2, err3}, {1, err2}, {0, err}, {0, err4}},
+		},
+		{
+			name:     "stop early",
+			options:  nil,
+			limit:    2,
+			expected: []walkedError{{0, err}, {1, err2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]func(*Tracer) error{DetailedOutput(false)}, tt.options...)
+			tracer, constructErr := NewMultiTracer([]error{err3, err4}, options...)
+			assert.Nil(t, constructErr)
+
+			walked := []walkedError{}
+			walkErr := tracer.Walk(func(depth int, err error) bool {
+				walked = append(walked, walkedError{depth, err})
+
+				return len(walked) != tt.limit
+			})
+			assert.Nil(t, walkErr)
+			assert.Equal(t, tt.expected, walked)
+
+			// The Tracer should not have been read from.
+			message, readErr := tracer.ReadNext()
+			assert.Nil(t, readErr)
+			assert.Equal(t, plainMessage(tt.expected[0].err), message)
+		})
+	}
+}
+
+func TestTracer_Path(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T) *Tracer
+		expected string
+	}{
+		{
+			name: "oldest first",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things\nbroke :(")))
+				tracer, constructErr := NewTracer(err, Ordering(OldestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			expected: "things broke :( << aw shucks << oh no",
+		},
+		{
+			name: "newest first",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things\nbroke :(")))
+				tracer, constructErr := NewTracer(err, Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			expected: "things broke :( << aw shucks << oh no",
+		},
+		{
+			name: "multiple errors",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewMultiTracer([]error{err, nil, errors.New("oh no")})
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			expected: "things broke :( << aw shucks; oh no",
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := tt.setup(t)
+			assert.Equal(t, tt.expected, tracer.Path(" << "))
+			// Nothing should be consumed, so the path should be the same again
+			assert.Equal(t, tt.expected, tracer.Path(" << "))
+		})
+	}
+}
+
+func TestTracer_TypeCounts(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewMultiTracer([]error{err3, nil, tracerTestError{}})
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.ReadNext()
+				assert.Nil(t, err)
+
+				expected := map[string]int{
+					"*errors.errorString":    1,
+					"*xerrors.wrapError":     2,
+					"xtrace.tracerTestError": 1,
+				}
+				assert.Equal(t, expected, tracer.TypeCounts())
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				assert.Equal(t, map[string]int{}, tracer.TypeCounts())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Exhausted(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "ReadNext",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for !tracer.Exhausted() {
+					_, err := tracer.ReadNext()
+					assert.Nil(t, err)
+				}
+
+				_, err := tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "Read",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(errors.New("things broke :("))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := make([]byte, 5)
+				_, err := tracer.Read(buffer)
+				assert.Nil(t, err)
+				assert.False(t, tracer.Exhausted())
+
+				for !tracer.Exhausted() {
+					_, err = tracer.Read(buffer)
+					assert.Nil(t, err)
+				}
+
+				_, err = tracer.Read(buffer)
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				assert.True(t, tracer.Exhausted())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestEOFWithLastMessage(t *testing.T) {
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, constructErr := NewTracer(err, DetailedOutput(false), EOFWithLastMessage(true))
+	assert.Nil(t, constructErr)
+
+	message, readErr := tracer.ReadNext()
+	assert.Nil(t, readErr)
+	assert.Equal(t, "things broke :(", message)
+
+	message, readErr = tracer.ReadNext()
+	assert.True(t, IsEnd(readErr))
+	assert.Equal(t, "aw shucks", message)
+
+	message, readErr = tracer.ReadNext()
+	assert.True(t, IsEnd(readErr))
+	assert.Equal(t, "", message)
+
+	// Full traces must be unaffected.
+	assert.Equal(t, "things broke :(\naw shucks", fmt.Sprintf("%v", tracer))
+}
+
+func TestIsEnd(t *testing.T) {
+	assert.True(t, IsEnd(io.EOF))
+	assert.False(t, IsEnd(nil))
+	assert.False(t, IsEnd(errors.New("things broke :(")))
+}
+
+func TestTracer_SeekTo(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "seek backwards and forwards",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				err := tracer.SeekTo(2)
+				assert.Nil(t, err)
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+
+				err = tracer.SeekTo(1)
+				assert.Nil(t, err)
+				message, err = tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "aw shucks", message)
+
+				err = tracer.SeekTo(0)
+				assert.Nil(t, err)
+				message, err = tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "I tried very hard and failed", message)
+			},
+		},
+		{
+			name: "out of range",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				assert.NotNil(t, tracer.SeekTo(2))
+				assert.NotNil(t, tracer.SeekTo(-1))
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_At(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "random access",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				err3 := xerrors.Errorf("I tried very hard and failed: %w", err2)
+				tracer, constructErr := NewTracer(err3, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				expectedMessages := map[int]string{
+					2: "things broke :(",
+					0: "I tried very hard and failed",
+					1: "aw shucks",
+				}
+				for _, index := range []int{2, 0, 1} {
+					message, err := tracer.At(index)
+					assert.Nil(t, err)
+					assert.Equal(t, expectedMessages[index], message)
+				}
+
+				// The Tracer should not have been read from.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "I tried very hard and failed", message)
+			},
+		},
+		{
+			name: "stateful formatter",
+			setup: func(t *testing.T) *Tracer {
+				formatter, constructErr := NewGlobalDedupeFormatter()
+				if constructErr != nil {
+					return handleTracerTestSetupError(t, nil, constructErr)
+				}
+
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("things broke :(: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Formatter(formatter))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for i := 0; i < 2; i++ {
+					message, err := tracer.At(1)
+					assert.Nil(t, err)
+					assert.Equal(t, "things broke :( (repeated)", message)
+				}
+			},
+		},
+		{
+			name: "dropped error",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), Formatter(dropFormatter{}))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.At(1)
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "out of range",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				_, err := tracer.At(2)
+				assert.NotNil(t, err)
+				_, err = tracer.At(-1)
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestFormatterFunc(t *testing.T) {
+	formatters := []*GlobalDedupeFormatter{}
+	factory := func() (TraceFormatter, error) {
+		formatter, err := NewGlobalDedupeFormatter()
+		if err != nil {
+			return nil, err
+		}
+
+		formatters = append(formatters, formatter)
+
+		return formatter, nil
+	}
+
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, err := NewTracer(err, DetailedOutput(false), FormatterFunc(factory))
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "things broke :(\naw shucks", fmt.Sprintf("%v", tracer))
+	}
+
+	// One formatter for the Tracer itself, and one for each copy made by Format.
+	assert.Len(t, formatters, 3)
+	assert.True(t, formatters[1] != formatters[2])
+}
+
+func TestFormatterFunc_Error(t *testing.T) {
+	factory := func() (TraceFormatter, error) {
+		return nil, errors.New("can not make formatter")
+	}
+
+	_, err := NewTracer(errors.New("things broke :("), FormatterFunc(factory))
+	assert.NotNil(t, err)
+
+	_, err = NewTracer(errors.New("things broke :("), FormatterFunc(nil))
+	assert.NotNil(t, err)
+}
+
+func TestWithBOM(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "trace",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke"))
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), WithBOM(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				for i := 0; i < 2; i++ {
+					output := bytes.NewBufferString("")
+					assert.Nil(t, tracer.Trace(output))
+					assert.Equal(t, "\xef\xbb\xbfthings broke\naw shucks", output.String())
+				}
+			},
+		},
+		{
+			name: "read",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke"))
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), WithBOM(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				output := bytes.NewBufferString("")
+				_, err := output.ReadFrom(tracer)
+				assert.Nil(t, err)
+				assert.Equal(t, "\xef\xbb\xbfthings brokeaw shucks", output.String())
+				assert.Equal(t, 1, strings.Count(output.String(), "\xef\xbb\xbf"))
+			},
+		},
+		{
+			name: "read next",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke")
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), WithBOM(true))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke", message)
+			},
+		},
+		{
+			name: "with line prefix and multiple groups",
+			setup: func(t *testing.T) *Tracer {
+				errs := []error{errors.New("things broke"), errors.New("oh no")}
+				tracer, constructErr := NewMultiTracer(
+					errs,
+					DetailedOutput(false),
+					WithBOM(true),
+					LinePrefix(func() string { return "> " }),
+				)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				output := bytes.NewBufferString("")
+				assert.Nil(t, tracer.Trace(output))
+				assert.Equal(t, "\xef\xbb\xbf> things broke\n> oh no", output.String())
+			},
+		},
+		{
+			name: "disabled",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke")
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), WithBOM(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				output := bytes.NewBufferString("")
+				assert.Nil(t, tracer.Trace(output))
+				assert.Equal(t, "things broke", output.String())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestVerboseFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		options  []func(*Tracer) error
+		expected string
+	}{
+		{
+			name:     "enabled",
+			err:      xerrors.Errorf("aw shucks: %w", verboseError{message: "things broke :("}),
+			options:  []func(*Tracer) error{VerboseFallback(true), DetailDepth(1)},
+			expected: "things broke :(\nmain.main\n\t/home/nick/main.go:12\naw shucks",
+		},
+		{
+			name:     "disabled",
+			err:      verboseError{message: "things broke :("},
+			options:  []func(*Tracer) error{VerboseFallback(false)},
+			expected: "things broke :(",
+		},
+		{
+			name:     "no detailed output",
+			err:      verboseError{message: "things broke :("},
+			options:  []func(*Tracer) error{VerboseFallback(true), DetailedOutput(false)},
+			expected: "things broke :(",
+		},
+		{
+			name:     "verbose output identical to message",
+			err:      errors.New("things broke :("),
+			options:  []func(*Tracer) error{VerboseFallback(true)},
+			expected: "things broke :(",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewTracer(tt.err, tt.options...)
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestPreserveIndent(t *testing.T) {
+	tests := []struct {
+		name     string
+		preserve bool
+		expected string
+	}{
+		{
+			name:     "enabled",
+			preserve: true,
+			expected: "things broke :(\n    main.main\n    /home/nick/main.go:12",
+		},
+		{
+			name:     "disabled",
+			preserve: false,
+			expected: "things broke :(\n    main.main    ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewNewLineFormatter(PreserveIndent(tt.preserve))
+			assert.Nil(t, err)
+
+			tracer, err := NewTracer(indentedDetailError{message: "things broke :("}, Formatter(formatter))
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestWrapFramesAt(t *testing.T) {
+	longMessage := "the operation could not be completed because things broke :("
+	longPath := "/home/nick/go/src/github.com/ollien/xtrace/main.go"
+	tests := []struct {
+		name     string
+		err      error
+		width    int
+		expected string
+	}{
+		{
+			name:  "long path",
+			err:   frameTestError{message: longMessage, function: "main.main", file: longPath},
+			width: 30,
+			expected: longMessage + "\nmain.main\n    /home/nick/go/src/\n        github.com/ollien/\n" +
+				"        xtrace/main.go:12",
+		},
+		{
+			name:     "path without separators",
+			err:      frameTestError{message: "things broke :(", function: "main.main", file: "abcdefghijklmnopqrstuv"},
+			width:    24,
+			expected: "things broke :(\nmain.main\n    abcdefghijklmnopqrst\n        uv:12",
+		},
+		{
+			name:     "short path",
+			err:      frameTestError{message: longMessage, function: "main.main", file: "/main.go"},
+			width:    30,
+			expected: longMessage + "\nmain.main\n    /main.go:12",
+		},
+		{
+			name:     "disabled",
+			err:      frameTestError{message: longMessage, function: "main.main", file: longPath},
+			width:    0,
+			expected: longMessage + "\nmain.main\n    " + longPath + ":12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewTracer(tt.err, WrapFramesAt(tt.width))
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestWrapFramesAt_Negative(t *testing.T) {
+	_, err := NewTracer(errors.New("things broke :("), WrapFramesAt(-1))
+	assert.NotNil(t, err)
+}
+
+func TestMaxLines_NotPositive(t *testing.T) {
+	_, err := NewTracer(errors.New("things broke :("), MaxLines(0))
+	assert.NotNil(t, err)
+}
+
+func TestShowCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		options  []func(*Tracer) error
+		expected string
+		counted  bool
+	}{
+		{
+			name:     "enabled",
+			errs:     []error{xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke")))},
+			options:  []func(*Tracer) error{ShowCount(true)},
+			expected: "3 errors:\nthings broke\naw shucks\noh no",
+			counted:  true,
+		},
+		{
+			name:     "disabled",
+			errs:     []error{xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke")))},
+			options:  []func(*Tracer) error{ShowCount(false)},
+			expected: "things broke\naw shucks\noh no",
+		},
+		{
+			name:     "single error",
+			errs:     []error{errors.New("things broke")},
+			options:  []func(*Tracer) error{ShowCount(true)},
+			expected: "1 error:\nthings broke",
+			counted:  true,
+		},
+		{
+			name:     "multiple top-level errors",
+			errs:     []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke")), errors.New("oh no")},
+			options:  []func(*Tracer) error{ShowCount(true)},
+			expected: "3 errors:\nthings broke\naw shucks\noh no",
+			counted:  true,
+		},
+		{
+			name: "localized",
+			errs: []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke"))},
+			options: []func(*Tracer) error{
+				ShowCount(true),
+				WithMessages(Messages{Count: "%d errores:", CountOne: "1 error:"}),
+			},
+			expected: "2 errores:\nthings broke\naw shucks",
+			counted:  true,
+		},
+		{
+			name:     "no errors",
+			errs:     []error{},
+			options:  []func(*Tracer) error{ShowCount(true)},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]func(*Tracer) error{DetailedOutput(false)}, tt.options...)
+			tracer, err := NewMultiTracer(tt.errs, options...)
+			assert.Nil(t, err)
+
+			numErrors := tracer.remainingErrorCount()
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, tt.expected, output.String())
+			if tt.counted {
+				assert.True(t, strings.HasPrefix(output.String(), fmt.Sprint(numErrors)))
+			}
+		})
+	}
+}
+
+func TestOnErrorTimed(t *testing.T) {
+	err := xerrors.Errorf("oh no: %w", xerrors.Errorf("aw shucks: %w", errors.New("things broke")))
+	indices := []int{}
+	durations := []time.Duration{}
+	tracer, constructErr := NewTracer(
+		err,
+		DetailedOutput(false),
+		Formatter(slowFormatter{delay: 5 * time.Millisecond}),
+		OnErrorTimed(func(index int, duration time.Duration) {
+			indices = append(indices, index)
+			durations = append(durations, duration)
+		}),
+	)
+	assert.Nil(t, constructErr)
+
+	output := bytes.NewBufferString("")
+	assert.Nil(t, tracer.Trace(output))
+	assert.Equal(t, []int{0, 1, 2}, indices)
+	for _, duration := range durations {
+		assert.True(t, duration >= 5*time.Millisecond, duration)
+	}
+}
+
+func TestOnErrorTimed_Nil(t *testing.T) {
+	_, err := NewTracer(errors.New("things broke"), OnErrorTimed(nil))
+	assert.NotNil(t, err)
+}
+
+func TestOnFormat(t *testing.T) {
+	type formatCall struct {
+		previous  []string
+		message   string
+		formatted string
+	}
+
+	calls := []formatCall{}
+	hook := func(previous []string, message, formatted string) {
+		calls = append(calls, formatCall{previous: previous, message: message, formatted: formatted})
+	}
+
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, constructErr := NewTracer(err, DetailedOutput(false), OnFormat(hook))
+	assert.Nil(t, constructErr)
+	plainTracer, constructErr := NewTracer(err, DetailedOutput(false))
+	assert.Nil(t, constructErr)
+
+	output := bytes.NewBufferString("")
+	assert.Nil(t, tracer.Trace(output))
+	plainOutput := bytes.NewBufferString("")
+	assert.Nil(t, plainTracer.Trace(plainOutput))
+	assert.Equal(t, plainOutput.String(), output.String())
+
+	expectedCalls := []formatCall{
+		{previous: nil, message: "things broke :(", formatted: "things broke :("},
+		{previous: nil, message: "aw shucks", formatted: "aw shucks"},
+	}
+	assert.Equal(t, expectedCalls, calls)
+
+	_, constructErr = NewTracer(err, OnFormat(nil))
+	assert.NotNil(t, constructErr)
+}
+
+func TestTracer_TraceFunc(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				messages := []string{}
+				err := tracer.TraceFunc(func(index int, message string) {
+					assert.Equal(t, len(messages), index)
+					messages = append(messages, message)
+				})
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"aw shucks", "things broke :("}, messages)
+
+				_, err = tracer.ReadNext()
+				assert.Equal(t, io.EOF, err)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_TraceDetailed(t *testing.T) {
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, constructErr := NewTracer(err, DetailedOutput(false))
+	assert.Nil(t, constructErr)
+
+	buffer := bytes.NewBufferString("")
+	traceErr := tracer.TraceDetailed(buffer)
+	assert.Nil(t, traceErr)
+	assert.Equal(t, fmt.Sprintf("%+v", tracer), buffer.String())
+	assert.Contains(t, buffer.String(), "tracer_test.go")
+
+	// The Tracer itself should be unaffected.
+	message, readErr := tracer.ReadNext()
+	assert.Nil(t, readErr)
+	assert.Equal(t, "things broke :(", message)
+}
+
+func TestTracer_TraceTerse(t *testing.T) {
+	err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	tracer, constructErr := NewTracer(err, DetailedOutput(true))
+	assert.Nil(t, constructErr)
+
+	buffer := bytes.NewBufferString("")
+	traceErr := tracer.TraceTerse(buffer)
+	assert.Nil(t, traceErr)
+	assert.Equal(t, "things broke :(\naw shucks", buffer.String())
+}
+
+func TestTracer_TraceHybrid(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New(`things "broke" <:(>`)
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false), Ordering(NewestFirstOrdering))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.TraceHybrid(buffer)
+				assert.Nil(t, err)
+
+				expected := "aw shucks\nthings \"broke\" <:(>\n" +
+					"--- xtrace json ---\n" +
+					`{"errors":[{"depth":1,"message":"aw shucks"},` +
+					`{"depth":0,"message":"things \"broke\" <:(>"}]}` + "\n"
+				assert.Equal(t, expected, buffer.String())
+			},
+		},
+		{
+			name: "fields",
+			setup: func(t *testing.T) *Tracer {
+				err := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+				tracer, constructErr := NewTracer(err, DetailedOutput(false), WithField("request_id", "abc123"))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.TraceHybrid(buffer)
+				assert.Nil(t, err)
+
+				expected := "request_id=abc123\nthings broke :(\naw shucks\n" +
+					"--- xtrace json ---\n" +
+					`{"fields":{"request_id":"abc123"},"errors":[{"depth":0,"message":"things broke :("},` +
+					`{"depth":1,"message":"aw shucks"}]}` + "\n"
+				assert.Equal(t, expected, buffer.String())
+			},
+		},
+		{
+			name: "nil error",
+			setup: func(t *testing.T) *Tracer {
+				tracer, constructErr := NewTracer(nil)
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				buffer := bytes.NewBufferString("")
+				err := tracer.TraceHybrid(buffer)
+				assert.Nil(t, err)
+				assert.Equal(t, "--- xtrace json ---\n{\"errors\":[]}\n", buffer.String())
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_Base64Reader(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding *base64.Encoding
+		decoding *base64.Encoding
+	}{
+		{
+			name:     "default encoding",
+			encoding: nil,
+			decoding: base64.StdEncoding,
+		},
+		{
+			name:     "url encoding",
+			encoding: base64.RawURLEncoding,
+			decoding: base64.RawURLEncoding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := xerrors.Errorf("aw shucks?: %w", errors.New("things broke >:("))
+			tracer, constructErr := NewTracer(err)
+			assert.Nil(t, constructErr)
+
+			expected := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(expected))
+
+			encoded := bytes.NewBufferString("")
+			_, readErr := encoded.ReadFrom(tracer.Base64Reader(tt.encoding))
+			assert.Nil(t, readErr)
+
+			decoded, decodeErr := tt.decoding.DecodeString(encoded.String())
+			assert.Nil(t, decodeErr)
+			assert.Equal(t, expected.String(), string(decoded))
+		})
+	}
+}
+
+type asErrorTarget struct {
+	code int
+}
+
+func (err asErrorTarget) Error() string {
+	return fmt.Sprintf("code %d", err.code)
+}
+
+func TestTracer_AsError(t *testing.T) {
+	rootErr := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", rootErr)
+	tracer, err := NewTracer(err2, DetailedOutput(false))
+	assert.Nil(t, err)
+
+	tracedErr := tracer.AsError()
+	assert.Equal(t, "things broke :(\naw shucks", tracedErr.Error())
+	assert.True(t, errors.Is(tracedErr, rootErr))
+	assert.True(t, errors.Is(xerrors.Errorf("request failed: %w", tracedErr), rootErr))
+	assert.False(t, errors.Is(tracedErr, io.EOF))
+	assert.Equal(t, err2, errors.Unwrap(tracedErr))
+
+	// The Tracer should not have been read from.
+	message, err := tracer.ReadNext()
+	assert.Nil(t, err)
+	assert.Equal(t, "things broke :(", message)
+}
+
+func TestTracer_AsError_MultiTracer(t *testing.T) {
+	rootErr := errors.New("things broke :(")
+	errs := []error{xerrors.Errorf("aw shucks: %w", asErrorTarget{code: 42}), rootErr}
+	tracer, err := NewMultiTracer(errs, DetailedOutput(false))
+	assert.Nil(t, err)
+
+	tracedErr := tracer.AsError()
+	assert.Equal(t, "code 42\naw shucks\nthings broke :(", tracedErr.Error())
+	assert.True(t, errors.Is(tracedErr, rootErr))
+	assert.Nil(t, errors.Unwrap(tracedErr))
+
+	target := asErrorTarget{}
+	assert.True(t, errors.As(tracedErr, &target))
+	assert.Equal(t, 42, target.code)
+}
+
+func TestTracer_AsError_Nil(t *testing.T) {
+	tracer, err := NewTracer(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tracer.AsError())
+}
+
+func TestTracer_TraceStringBuilder(t *testing.T) {
+	tests := []tracerTest{
+		{
+			name: "wrapped errors",
+			setup: func(t *testing.T) *Tracer {
+				err := errors.New("things broke :(")
+				err2 := xerrors.Errorf("aw shucks: %w", err)
+				tracer, constructErr := NewTracer(err2, DetailedOutput(false))
+
+				return handleTracerTestSetupError(t, tracer, constructErr)
+			},
+			testFunc: func(t *testing.T, tracer *Tracer) {
+				out, err := tracer.TraceStringBuilder()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(\naw shucks", out)
+
+				// The Tracer should not have been read from.
+				message, err := tracer.ReadNext()
+				assert.Nil(t, err)
+				assert.Equal(t, "things broke :(", message)
+			},
+		},
+	}
+
+	runTracerTestTable(t, tests)
+}
+
+func TestTracer_TraceMatching(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errTimeout := errors.New("timed out")
+	err1 := xerrors.Errorf("could not load user: %w", errNotFound)
+	err2 := xerrors.Errorf("retrying: %w", err1)
+	err3 := xerrors.Errorf("request failed: %w", xerrors.Errorf("fetch: %w", errTimeout))
+
+	tests := []struct {
+		name     string
+		errs     []error
+		targets  []error
+		expected string
+	}{
+		{
+			name:     "some errors match",
+			errs:     []error{err2},
+			targets:  []error{errNotFound},
+			expected: "not found",
+		},
+		{
+			name:     "any of several targets",
+			errs:     []error{err2, err3},
+			targets:  []error{errNotFound, errTimeout, err1},
+			expected: "not found\ncould not load user\ntimed out",
+		},
+		{
+			name:     "no errors match",
+			errs:     []error{err2},
+			targets:  []error{errTimeout},
+			expected: "",
+		},
+		{
+			name:     "no targets",
+			errs:     []error{err2},
+			targets:  nil,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewMultiTracer(tt.errs, DetailedOutput(false))
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.TraceMatching(output, tt.targets...))
+			assert.Equal(t, tt.expected, output.String())
+
+			// The Tracer itself should still hold all of its errors.
+			message, err := tracer.ReadNext()
+			assert.Nil(t, err)
+			assert.Equal(t, "not found", message)
+		})
+	}
+}
+
+func TestTracer_TraceN(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		options []func(*Tracer) error
+	}{
+		{
+			name: "detailed output",
+			errs: []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))},
+		},
+		{
+			name:    "line prefix",
+			errs:    []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke ☹"))},
+			options: []func(*Tracer) error{DetailedOutput(false), LinePrefix(func() string { return "> " })},
+		},
+		{
+			name:    "truncated",
+			errs:    []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))},
+			options: []func(*Tracer) error{DetailedOutput(false), MaxBytes(8)},
+		},
+		{
+			name: "no errors",
+			errs: []error{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewMultiTracer(tt.errs, tt.options...)
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			n, err := tracer.TraceN(output)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(output.Len()), n)
+		})
+	}
+}
+
+func TestTracer_Size(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		options []func(*Tracer) error
+	}{
+		{
+			name: "detailed output",
+			errs: []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))},
+		},
+		{
+			name: "nested formatter and multi-byte characters",
+			errs: []error{xerrors.Errorf("aw shucks: %w", errors.New("things broke ☹"))},
+			options: []func(*Tracer) error{
+				DetailedOutput(false),
+				FormatterFunc(func() (TraceFormatter, error) {
+					return NewNestedMessageFormatter()
+				}),
+			},
+		},
+		{
+			name: "multiple groups with fields",
+			errs: []error{errors.New("things broke :("), errors.New("oh no")},
+			options: []func(*Tracer) error{
+				DetailedOutput(false),
+				GroupSeparator("\n---\n"),
+				WithField("request_id", "abc123"),
+			},
+		},
+		{
+			name: "no errors",
+			errs: []error{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewMultiTracer(tt.errs, tt.options...)
+			assert.Nil(t, err)
+
+			size, err := tracer.Size()
+			assert.Nil(t, err)
+
+			output := bytes.NewBufferString("")
+			assert.Nil(t, tracer.Trace(output))
+			assert.Equal(t, output.Len(), size)
+		})
+	}
+}
+
+func BenchmarkTracer_TraceStringBuilder(b *testing.B) {
+	err := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err)
+	tracer, _ := NewTracer(err2)
+	b.Run("strings.Builder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = tracer.TraceStringBuilder()
+		}
+	})
+
+	b.Run("bytes.Buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buffer := bytes.NewBufferString("")
+			_ = tracer.Trace(buffer)
+			_ = buffer.String()
+		}
+	})
+}
+
+func BenchmarkTracer_ReadNext(b *testing.B) {
+	err := errors.New("things broke :(")
+	for i := 0; i < 100; i++ {
+		err = xerrors.Errorf("aw shucks: %w", err)
+	}
+
+	b.Run("synchronized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tracer, _ := NewTracer(err, DetailedOutput(false))
+			for _, readErr := tracer.ReadNext(); readErr != io.EOF; _, readErr = tracer.ReadNext() {
+			}
+		}
+	})
+
+	b.Run("unsynchronized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tracer, _ := NewTracer(err, DetailedOutput(false), Unsynchronized(true))
+			for _, readErr := tracer.ReadNext(); readErr != io.EOF; _, readErr = tracer.ReadNext() {
+			}
+		}
+	})
+}
+
+// moduleFramePrinter is an xerrors.Printer that drops any frames whose function is not within github.com/myorg.
+type moduleFramePrinter struct {
+	xerrors.Printer
+	// Whether or not the next file and line number should be dropped
+	dropNextLocation bool
+}
+
+func (printer *moduleFramePrinter) Printf(format string, args ...interface{}) {
+	// xerrors prints the function of a frame, followed by its file and line number.
+	if format == "%s\n    " && !strings.HasPrefix(fmt.Sprint(args...), "github.com/myorg/") {
+		printer.dropNextLocation = true
+		return
+	} else if format == "%s:%d\n" && printer.dropNextLocation {
+		printer.dropNextLocation = false
+		return
+	}
+
+	printer.Printer.Printf(format, args...)
+}
+
+func TestDetailPrinter(t *testing.T) {
+	baseErr := xerrors.Errorf("aw shucks: %w", errors.New("things broke :("))
+	printCalls := 0
+	wrapPrinter := func(printer xerrors.Printer) xerrors.Printer {
+		printCalls++
+
+		return &moduleFramePrinter{Printer: printer}
+	}
+
+	tracer, err := NewTracer(baseErr, DetailPrinter(wrapPrinter))
+	assert.Nil(t, err)
+
+	out := fmt.Sprintf("%+v", tracer)
+	assert.Equal(t, "things broke :(\naw shucks", out)
+	// Only "aw shucks" implements xerrors.Formatter, and its detailed output is only rendered once.
+	assert.Equal(t, 1, printCalls)
+}
+
+type capsFormatter struct{}
+
+func (formatter capsFormatter) FormatTrace(previous []string, message string) string {
+	return strings.ToUpper(message)
+}
+
+// slowFormatter is a formatter that sleeps for the given delay before returning each message as is.
+type slowFormatter struct {
+	delay time.Duration
+}
+
+func (formatter slowFormatter) FormatTrace(previous []string, message string) string {
+	time.Sleep(formatter.delay)
+
+	return message
+}
+
+func ExampleNewTracer() {
+	baseErr := errors.New("aw shucks, something broke")
+	// capsFormatter is a custom formatter that simply applies strings.ToUpper to all messages
+	tracer, err := NewTracer(baseErr, Formatter(capsFormatter{}))
+	if err != nil {
+		panic("can not make tracer")
+	}
+	output, err := tracer.ReadNext()
+	if err != nil {
+		panic("can not read from tracer")
+	}
+
+	fmt.Println(output)
+	// Output: AW SHUCKS, SOMETHING BROKE
+}
+
+func ExampleTracer_Format() {
+	baseErr := errors.New("aw shucks, something broke")
+	err2 := xerrors.Errorf("things went wrong!: %w", baseErr)
+	tracer, err := NewTracer(err2)
+	if err != nil {
+		panic("can not make tracer")
+	}
+
+	fmt.Printf("%v", tracer)
+	// Output: aw shucks, something broke
+	// things went wrong!
+}
+
+func ExampleOrdering() {
+	baseErr := errors.New("aw shucks, something broke")
+	err2 := xerrors.Errorf("things went wrong!: %w", baseErr)
 	tracer, err := NewTracer(err2, Ordering(NewestFirstOrdering))
 	if err != nil {
 		panic("can not make tracer")
@@ -539,3 +3758,19 @@ func ExampleOrdering() {
 	// Output: things went wrong!
 	// aw shucks, something broke
 }
+
+func ExampleDetailPrinter() {
+	baseErr := errors.New("aw shucks, something broke")
+	err2 := xerrors.Errorf("things went wrong!: %w", baseErr)
+	// moduleFramePrinter is an xerrors.Printer that drops all frames that are not within github.com/myorg
+	tracer, err := NewTracer(err2, DetailPrinter(func(printer xerrors.Printer) xerrors.Printer {
+		return &moduleFramePrinter{Printer: printer}
+	}))
+	if err != nil {
+		panic("can not make tracer")
+	}
+
+	fmt.Printf("%+v", tracer)
+	// Output: aw shucks, something broke
+	// things went wrong!
+}
@@ -16,7 +16,15 @@ package xtrace
    limitations under the License.
 */
 
-import "errors"
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
 
 // TraceOrderingMethod represents a way to order the errors within the produced trace.
 type TraceOrderingMethod int
@@ -30,6 +38,54 @@ const (
 	NewestFirstOrdering
 )
 
+// NilRenderingMode determines what is written by TraceWith when it is given a nil error. See the NilRendering option.
+type NilRenderingMode struct {
+	output string
+}
+
+var (
+	// Empty writes nothing for a nil error.
+	Empty = NilRenderingMode{}
+	// Newline writes a single newline for a nil error, as the trace of a nil error is empty (default).
+	Newline = NilRenderingMode{output: "\n"}
+)
+
+// Placeholder writes the given text, followed by a newline, for a nil error (e.g. "<no error>").
+func Placeholder(text string) NilRenderingMode {
+	return NilRenderingMode{output: text + "\n"}
+}
+
+// Messages holds the user-facing strings that a Tracer may write in place of, or in addition to, the errors it traces.
+// These can be overridden with the WithMessages option, such as to localize the output of a Tracer.
+type Messages struct {
+	// Empty is written in place of an error whose message is empty. Defaults to "<empty>".
+	Empty string
+	// Truncated is written in place of the remainder of a trace that was cut off by the MaxBytes or MaxLines options.
+	// Defaults to "... (truncated)".
+	Truncated string
+	// Count is written before a trace when the ShowCount option is enabled, with "%d" replaced by the number of errors
+	// in the trace. Defaults to "%d errors:".
+	Count string
+	// CountOne is written in place of Count when there is exactly one error in the trace. Defaults to "1 error:".
+	CountOne string
+	// Because labels the root cause of each chain when the Tracer's formatter is a NarrativeFormatter. Defaults to
+	// "Because:".
+	Because string
+	// WhichCaused labels each error that wraps another when the Tracer's formatter is a NarrativeFormatter. Defaults
+	// to "Which caused:".
+	WhichCaused string
+}
+
+// defaultMessages holds the English strings used by a Tracer, unless they are overridden with WithMessages.
+var defaultMessages = Messages{
+	Empty:       emptyError,
+	Truncated:   truncationMarker,
+	Count:       "%d errors:",
+	CountOne:    "1 error:",
+	Because:     "Because:",
+	WhichCaused: "Which caused:",
+}
+
 // DetailedOutput will enable detailed output when this is passed to NewTracer. While the specifics of this detailed
 // output is defined by the xerrors.Formatter for the passed error, it will generally provide more detailed information
 // about the error, such as the file and line number of the error. Defaults to true.
@@ -41,25 +97,573 @@ func DetailedOutput(enabled bool) func(*Tracer) error {
 	}
 }
 
+// DetailDepth will limit detailed output to the n deepest errors in each chain (i.e. the root cause and the n - 1
+// errors closest to it), when this is passed to NewTracer. All other errors will be outputted without detail. This has
+// no effect if detailed output is disabled, such as with DetailedOutput(false) or when formatting with %v. Passing
+// zero will use detailed output for all errors. Defaults to zero.
+func DetailDepth(n int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if n < 0 {
+			return errors.New("detail depth must not be negative")
+		}
+
+		tracer.detailDepth = n
+
+		return nil
+	}
+}
+
+// PreferCause will make the Tracer generated by NewTracer build its chain by following the Cause method of each error
+// (as used by github.com/pkg/errors), when this is passed to it. If an error has both a Cause and an Unwrap method,
+// Cause takes precedence; errors without a Cause method are still unwrapped as usual. This is mainly useful when
+// migrating between the two conventions. Note that an error's Cause and Unwrap methods may return different errors, in
+// which case the chain that is traced will differ from the one that errors.Is and errors.As search. Defaults to false.
+func PreferCause(prefer bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.preferCause = prefer
+
+		return nil
+	}
+}
+
+// StopAt will cut off each chain of the Tracer generated by NewTracer at the first error that the given predicate
+// returns true for, when this is passed to it, such as to leave out the errors of a framework that the errors of
+// interest are wrapped in. The chain is always searched from the outermost error towards the root cause, regardless of
+// the ordering, and the predicate is given each error without unwrapping it. If inclusive is set, the matching error is
+// kept, and the errors that it wraps are left out; otherwise, the matching error is left out as well. The errors that
+// are left out are treated as if they were not in the chain at all, so the Ordering option then applies to the errors
+// that remain (i.e. with OldestFirstOrdering, the trace starts at the matching error, or the error that wraps it). If
+// the predicate does not match any error, the chain is left as is, and if inclusive is not set and the outermost error
+// matches, nothing is left to trace. For a Tracer constructed with NewMultiTracer, each top-level error's chain is cut
+// off separately.
+func StopAt(predicate func(error) bool, inclusive bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if predicate == nil {
+			return errors.New("nil predicate provided to StopAt")
+		}
+
+		tracer.stopAt = predicate
+		tracer.stopAtInclusive = inclusive
+
+		return nil
+	}
+}
+
+// EOFWithLastMessage will make ReadNext return io.EOF along with the message of the last error of the Tracer generated
+// by NewTracer, when this is passed to it, rather than on the following call. This follows the convention of
+// io.Reader, where data may be returned along with io.EOF. Subsequent calls will continue to return io.EOF with an
+// empty message. Note that if the formatter drops all of the remaining errors, the last message can not be known
+// ahead of time, so io.EOF will be returned on the following call as usual. Defaults to false.
+func EOFWithLastMessage(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.eofWithLastMessage = enabled
+
+		return nil
+	}
+}
+
+// SuppressDetailFor will disable detailed output for errors of the same concrete types as the given errors, when this
+// is passed to NewTracer, while other errors keep their detailed output. The given errors are only used for their
+// types. Types are matched by identity, against each error in the chain on its own; unlike errors.As, an error is not
+// matched by the errors it wraps, nor by its As method, and an error of type *T does not match one of type T.
+// Passing this more than once will suppress detailed output for all of the types given.
+func SuppressDetailFor(types ...error) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		for _, errType := range types {
+			if errType == nil {
+				return errors.New("nil error provided to SuppressDetailFor")
+			}
+
+			tracer.suppressedDetailTypes = append(tracer.suppressedDetailTypes, reflect.TypeOf(errType))
+		}
+
+		return nil
+	}
+}
+
+// OnFormat sets a function that is called after each call to the formatter's FormatTrace (or FormatTraceWithContext)
+// method, when this is passed to NewTracer, with the arguments the formatter was given and the string it produced. This
+// is intended only for debugging custom formatters, and has no effect on the output; the previous messages must not be
+// modified. The function is invoked synchronously while the Tracer's read lock is held, so it must not read from the
+// Tracer itself. Defaults to no function.
+func OnFormat(hook func(previous []string, message, formatted string)) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if hook == nil {
+			return errors.New("nil function provided to OnFormat")
+		}
+
+		tracer.onFormat = hook
+
+		return nil
+	}
+}
+
+// OnErrorTimed sets a hook that is called with the index of each error (see TraceContext) and the time that was taken
+// to produce its output, when this is passed to NewTracer. The time includes the execution of the formatter for each of
+// the error's messages, and of the error's own FormatError method, which makes this useful for finding slow formatters
+// during development. The hook is called once each time an error is formatted, including by Root and Top. It is
+// invoked synchronously while the Tracer's read lock is held, so it must not read from the Tracer itself. Defaults to
+// no function, in which case no time is measured.
+func OnErrorTimed(hook func(index int, duration time.Duration)) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if hook == nil {
+			return errors.New("nil function provided to OnErrorTimed")
+		}
+
+		tracer.onErrorTimed = hook
+
+		return nil
+	}
+}
+
+// NilRendering sets what is written by TraceWith when it is given a nil error, when this is passed to it. This has no
+// effect on a Tracer used directly. Defaults to Newline, which is what Trace always writes.
+func NilRendering(mode NilRenderingMode) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.nilRendering = mode
+
+		return nil
+	}
+}
+
+// LinePrefix sets a function that produces a prefix to write before every line of a full trace, such as when using
+// Trace, when this is passed to NewTracer. This allows each line to be tagged with, for instance, a request ID, so that
+// traces written concurrently can be told apart. The function is called once at the start of each full trace, and the
+// result is used for every line of it. The prefix does not count towards the limit set by MaxBytes, and does not apply
+// to the Read methods. Defaults to no prefix.
+func LinePrefix(prefixFunc func() string) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if prefixFunc == nil {
+			return errors.New("nil function provided to LinePrefix")
+		}
+
+		tracer.linePrefix = prefixFunc
+
+		return nil
+	}
+}
+
+// StripCommonPrefix will remove the longest prefix ending in ": " that is shared by the messages of all of the errors
+// being traced (e.g. "myservice: ") from each of them, when this is passed to NewTracer. Full traces, such as those
+// written by Trace, start with the removed prefix on a line of its own, with its surrounding whitespace removed, so it
+// is only outputted once; the Read methods only output the stripped messages. The prefix is found from the messages of
+// the errors before any formatting is applied, and is only removed from the start of each error's message, not from
+// its detailed output. No prefix is removed if there are fewer than two errors. As this is found from all of the
+// errors, it is computed when the Tracer is constructed. Defaults to false.
+func StripCommonPrefix(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.stripCommonPrefix = enabled
+
+		return nil
+	}
+}
+
+// WithField attaches a field, such as a request ID, to the trace when this is passed to NewTracer. Fields describe the
+// trace as a whole, rather than any single error, and are surfaced as follows:
+//
+//   - Full traces, such as those written by Trace, start with a header line that holds all of the fields. By default,
+//     this is a line of logfmt-style pairs (e.g. "request_id=abc123"), sorted by key; formatters that implement
+//     FieldsFormatter may write it differently, such as YAMLFormatter and DOTFormatter, which write it as a comment.
+//   - TraceHybrid includes the fields in its machine-readable section.
+//   - Formatters may read the fields from the Fields member of TraceContext.
+//
+// The Read methods do not output the fields. This may be passed more than once to attach more than one field; if the
+// same key is given more than once, the last value is used.
+func WithField(key string, value interface{}) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if key == "" {
+			return errors.New("empty key provided to WithField")
+		}
+
+		if tracer.fields == nil {
+			tracer.fields = map[string]interface{}{}
+		}
+
+		tracer.fields[key] = value
+
+		return nil
+	}
+}
+
+// ShowSource will show the given number of lines of source code on either side of each file and line number reference
+// in the detailed output of each error (e.g. "/home/nick/main.go:12"), when this is passed to NewTracer, as follows.
+//
+//	/home/nick/main.go:12
+//	    11 | 	doThings()
+//	    12 > 	panic("things broke :(")
+//	    13 | }
+//
+// The source is read from disk as each error is formatted, so references to files that can not be read, such as those
+// of a binary that is run on another machine, are left as is. As every reference requires a file to be read, this is
+// expensive, and is only intended for local debugging. Additionally, the files named in detailed output are read
+// without any further checks, and their contents are included in the trace, so this should not be used for errors that
+// come from untrusted sources, or where the trace may be seen by those who should not see the source. This has no
+// effect without detailed output. Defaults to 0, which shows no source.
+func ShowSource(lines int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if lines < 0 {
+			return errors.New("number of lines of source must not be negative")
+		}
+
+		tracer.sourceLines = lines
+
+		return nil
+	}
+}
+
+// VerboseFallback will give each error that does not implement xerrors.Formatter the output of formatting it with %+v
+// as its detailed output, when this is passed to NewTracer. This captures whatever such an error chooses to print in
+// its verbose form, such as the stack traces of some error packages, which would otherwise be lost, as only the
+// message of such an error is outputted. If the output of %+v starts with the message of the error, only the rest of
+// it is used. This only has an effect when detailed output is enabled for the error. Defaults to false, as the output
+// of %+v may be surprising for errors that do not expect it to be used.
+func VerboseFallback(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.verboseFallback = enabled
+
+		return nil
+	}
+}
+
+// WrapFramesAt will wrap each line of detailed output that is longer than width characters, such as the lines holding
+// long file paths, when this is passed to NewTracer. Wrapped lines are indented by four spaces more than the line they
+// belong to, and are broken after a path separator where possible. The messages of errors are never wrapped, nor is any
+// source shown with ShowSource. This has no effect without detailed output. Defaults to 0, which does not wrap.
+func WrapFramesAt(width int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if width < 0 {
+			return errors.New("frame width must not be negative")
+		}
+
+		tracer.frameWidth = width
+
+		return nil
+	}
+}
+
+// ShowHash will prefix each error in the trace with a short, stable hash of its message (e.g. "[0a1b2c3d] aw shucks")
+// when this is passed to NewTracer. The hash is the first eight hexadecimal characters of the FNV-1a hash of the
+// error's message, taken before any formatting is applied, so identical errors will share a hash across runs. Even when
+// detailed output is enabled, the hash is only computed from the non-detailed message, so that volatile details, such
+// as file paths and line numbers, do not affect it. Defaults to false.
+func ShowHash(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.showHash = enabled
+
+		return nil
+	}
+}
+
+// ShowCount will start each full trace with a line that holds the number of errors in it (e.g. "3 errors:") when this
+// is passed to NewTracer. The line is taken from the Count and CountOne fields of Messages, so it may be localized with
+// WithMessages. The count is of all of the errors in the trace, including those of every top-level error of a Tracer
+// constructed with NewMultiTracer, and those that are dropped by the formatter. Nothing is written if there are no
+// errors to trace. Defaults to false.
+func ShowCount(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.showCount = enabled
+
+		return nil
+	}
+}
+
+// WithBOM will start the output of the Tracer with a UTF-8 byte order mark (the bytes EF BB BF) when this is passed to
+// NewTracer, for tools that do not otherwise detect that the output is UTF-8. The byte order mark is written exactly
+// once: at the start of each full trace, such as those written by Trace or produced by Format, and at the start of the
+// first message read from the Tracer with Read. Other methods that return messages as strings, such as ReadNext, never
+// include it. Nothing is written if there are no errors to trace. Defaults to false.
+func WithBOM(enabled bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.withBOM = enabled
+
+		return nil
+	}
+}
+
+// DetailPrinter sets a function that will wrap the xerrors.Printer that is given to the FormatError method of each
+// error that implements xerrors.Formatter, when this is passed to NewTracer. The returned xerrors.Printer may intercept
+// any calls to Print, Printf, and Detail before passing them along (or not) to the given xerrors.Printer, giving
+// precise control over the output of each error, such as dropping certain frames from the detailed output.
+func DetailPrinter(wrapPrinter func(xerrors.Printer) xerrors.Printer) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.wrapPrinter = wrapPrinter
+
+		return nil
+	}
+}
+
+// DetailSeparator sets a string that will be inserted between the message of each error and its detailed output, when
+// this is passed to NewTracer. With the default formatter, each error's message already ends in a newline when it has
+// detailed output, so passing "\n" will leave a blank line between the message and its detailed output. This only
+// applies to errors that implement xerrors.Formatter and that have detailed output. Defaults to "".
+func DetailSeparator(separator string) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.detailSeparator = separator
+
+		return nil
+	}
+}
+
 // Formatter will set the given TracerFormatter as the formatter of the Tracer generated by NewTracer when this is
-// passed to it. Defaults to NewLineFormatter.
+// passed to it. The same formatter is shared with the copies of the Tracer made by Format, Trace, or From, so this is
+// best suited to stateless formatters; for stateful formatters, consider FormatterFunc. Defaults to NewLineFormatter.
 func Formatter(formatter TraceFormatter) func(*Tracer) error {
 	return func(tracer *Tracer) error {
 		tracer.formatter = formatter
+		tracer.formatterFactory = nil
+
+		return nil
+	}
+}
+
+// FormatterFunc will set the formatter of the Tracer generated by NewTracer to the result of the given factory when
+// this is passed to it. The factory is called again for each copy of the Tracer made by Format, Trace, or From, so each
+// of them is given a fresh formatter. Unlike Formatter, this makes it safe to use stateful formatters, such as
+// NewLineFormatter, without needing to reset them between traces.
+func FormatterFunc(factory func() (TraceFormatter, error)) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if factory == nil {
+			return errors.New("nil factory provided to FormatterFunc")
+		}
+
+		formatter, err := factory()
+		if err != nil {
+			return xerrors.Errorf("could not construct formatter: %w", err)
+		}
+
+		tracer.formatter = formatter
+		tracer.formatterFactory = factory
 
 		return nil
 	}
 }
 
-// Ordering sets the order in which the traces will be outputted from the Read methods, when passed to NewTracer.
-// Defaults to OldestFirstOrdering.
+// Ordering sets the order in which the traces will be outputted, both from the Read methods and in full traces, when
+// passed to NewTracer. Either may be overridden with ReadOrdering or TraceOrdering, respectively. This only changes the
+// order of the output; the depth of each error given to formatters is unaffected (see TraceContext and IndentByDepth).
+// Can not be combined with StableOrderingFunc. Defaults to OldestFirstOrdering.
 func Ordering(method TraceOrderingMethod) func(*Tracer) error {
 	return func(tracer *Tracer) error {
 		if method != OldestFirstOrdering && method != NewestFirstOrdering {
 			return errors.New("invalid ordering method provided to Tracer")
+		} else if tracer.orderingKey != nil {
+			return errors.New("Ordering can not be combined with StableOrderingFunc")
 		}
 
 		tracer.ordering = method
+		tracer.orderingSet = true
+
+		return nil
+	}
+}
+
+// ReadOrdering sets the order in which the errors will be outputted from the Read methods, and the other methods that
+// consume errors from the Tracer, when passed to NewTracer, in place of the order set by Ordering. Together with
+// TraceOrdering, this allows an interactive reader to read newest-first while full traces are written oldest-first, for
+// instance. Can not be combined with StableOrderingFunc. Defaults to the order set by Ordering.
+func ReadOrdering(method TraceOrderingMethod) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if method != OldestFirstOrdering && method != NewestFirstOrdering {
+			return errors.New("invalid ordering method provided to Tracer")
+		} else if tracer.orderingKey != nil {
+			return errors.New("ReadOrdering can not be combined with StableOrderingFunc")
+		}
+
+		tracer.readOrdering = &method
+		tracer.orderingSet = true
+
+		return nil
+	}
+}
+
+// TraceOrdering sets the order in which the errors will be outputted in full traces, such as those written by Trace or
+// by formatting the Tracer with %v, when passed to NewTracer, in place of the order set by Ordering. See ReadOrdering.
+// Can not be combined with StableOrderingFunc. Defaults to the order set by Ordering.
+func TraceOrdering(method TraceOrderingMethod) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if method != OldestFirstOrdering && method != NewestFirstOrdering {
+			return errors.New("invalid ordering method provided to Tracer")
+		} else if tracer.orderingKey != nil {
+			return errors.New("TraceOrdering can not be combined with StableOrderingFunc")
+		}
+
+		tracer.traceOrdering = &method
+		tracer.orderingSet = true
+
+		return nil
+	}
+}
+
+// StableOrderingFunc sorts the errors that will be outputted from the Read methods in ascending order of the given key,
+// when passed to NewTracer. Errors with equal keys are kept in the order of OldestFirstOrdering, so that the root cause
+// comes first among them. Each chain is sorted only once, before any of its errors are read; for a Tracer constructed
+// with NewMultiTracer, the errors are sorted within each top-level error's chain. Can not be combined with Ordering,
+// ReadOrdering, or TraceOrdering.
+func StableOrderingFunc(key func(error) int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if key == nil {
+			return errors.New("nil key provided to StableOrderingFunc")
+		} else if tracer.orderingSet {
+			return errors.New("StableOrderingFunc can not be combined with Ordering, ReadOrdering, or TraceOrdering")
+		}
+
+		tracer.orderingKey = key
+
+		return nil
+	}
+}
+
+// Buffer sets the buffer that will be used to hold the contents of the current error for the Read method of the Tracer
+// generated by NewTracer when this is passed to it. The buffer will be reset before it is used, so any existing
+// contents will be discarded. This allows buffers to be pooled and reused across Tracers. If nil is passed, the
+// Tracer's default buffer will be used. The buffer is never shared with the copies of the Tracer made by Format, Trace,
+// or From, nor with the Tracers made by a TracerFactory, each of which is given its own buffer.
+func Buffer(buffer *bytes.Buffer) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if buffer == nil || tracer.isClone {
+			return nil
+		}
+
+		buffer.Reset()
+		tracer.buffer = buffer
+
+		return nil
+	}
+}
+
+// SortBranches will sort the top-level errors of a Tracer generated by NewMultiTracer alphabetically by the message of
+// each top-level error, when this is passed to it. This gives stable output when the errors come from an unordered
+// source, such as a map. Only the order of the top-level errors is affected; the order of the errors within each
+// top-level error's chain is still controlled by Ordering. Top-level errors with equal messages are kept in the order
+// they were given in. Defaults to false.
+func SortBranches(sortBranches bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.sortBranches = sortBranches
+
+		return nil
+	}
+}
+
+// GroupSeparator sets the string that is written between the traces of each top-level error of a Tracer generated by
+// NewMultiTracer, when this is passed to it. This is written in addition to the newline that separates each error, so
+// passing "\n" will leave a blank line between each top-level error's trace. Defaults to "".
+func GroupSeparator(separator string) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.groupSeparator = separator
+
+		return nil
+	}
+}
+
+// Unsynchronized will disable the locking that ensures only one read can take place at a time in the Tracer generated
+// by NewTracer, when this is passed to it. This removes the overhead of locking on every read, but it is only safe to
+// do if the Tracer is never read from more than one goroutine at a time; reading from an unsynchronized Tracer
+// concurrently is a data race. Copies of the Tracer made by Format and Trace are never shared, so they are unaffected
+// by this. Defaults to false.
+func Unsynchronized(unsynchronized bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if unsynchronized {
+			tracer.readMux = noopLocker{}
+		} else {
+			tracer.readMux = &sync.Mutex{}
+		}
+
+		return nil
+	}
+}
+
+// RawBlock will make full traces (i.e. with Trace or Format) of the Tracer generated by NewTracer write each error
+// verbatim, when this is passed to it. Each error is written as the output of its Error method, or of formatting it
+// with %+v if detailed output is enabled, and the errors are separated by newlines and the GroupSeparator, if any. The
+// Tracer's formatter is ignored entirely, as are the other options that affect the output of each error, such as
+// ShowHash. Note that because the message of a wrapping error often contains the messages of the errors it wraps, this
+// may repeat much of the trace. Reading with Read or ReadNext is not affected by this. Defaults to false.
+func RawBlock(raw bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.rawBlock = raw
+
+		return nil
+	}
+}
+
+// KeepTrailing will make full traces (i.e. with Trace or Format) of the Tracer generated by NewTracer end with a
+// newline, when this is passed to it, so that several traces may be written to the same stream one after another. By
+// default, the newline that separates each error from the next is omitted after the last error. Nothing is written for
+// a trace with no errors, regardless of this setting. Defaults to false.
+func KeepTrailing(keep bool) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		tracer.keepTrailing = keep
+
+		return nil
+	}
+}
+
+// WithMessages overrides the user-facing strings written by the Tracer generated by NewTracer, when this is passed to
+// it. Any field of messages that is left empty keeps its default. Defaults to the English strings described by each
+// field of Messages.
+func WithMessages(messages Messages) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if messages.Empty != "" {
+			tracer.messages.Empty = messages.Empty
+		}
+
+		if messages.Truncated != "" {
+			tracer.messages.Truncated = messages.Truncated
+		}
+
+		if messages.Count != "" {
+			tracer.messages.Count = messages.Count
+		}
+
+		if messages.CountOne != "" {
+			tracer.messages.CountOne = messages.CountOne
+		}
+
+		if messages.Because != "" {
+			tracer.messages.Because = messages.Because
+		}
+
+		if messages.WhichCaused != "" {
+			tracer.messages.WhichCaused = messages.WhichCaused
+		}
+
+		return nil
+	}
+}
+
+// MaxBytes sets the maximum number of bytes that a full trace (i.e. with Trace or Format) of the Tracer generated by
+// NewTracer may write, when this is passed to it. Once the limit is reached, the trace is cut off, and
+// "... (truncated)", or the Truncated string set with WithMessages, is written in its place; this marker does not count
+// towards the limit. The trace will never be cut off in the middle of a UTF-8 sequence, so slightly fewer than n bytes
+// may be written before the marker. Neither the byte order mark written by WithBOM nor the prefix set with LinePrefix
+// count towards the limit. Reading with Read or ReadNext is not affected by this. Defaults to no limit.
+func MaxBytes(n int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if n <= 0 {
+			return errors.New("maximum number of bytes must be positive")
+		}
+
+		tracer.maxBytes = n
+
+		return nil
+	}
+}
+
+// MaxLines sets the maximum number of lines that a full trace (i.e. with Trace or Format) of the Tracer generated by
+// NewTracer may write, when this is passed to it, which is useful for panes of a fixed height, where the detailed
+// output of a single error may span many lines. Once n lines have been written, the trace is cut off, and
+// "... (truncated)", or the Truncated string set with WithMessages, is written on the line after them; this marker does
+// not count towards the limit. The trace is only cut off if there is more to write, so a trace of exactly n lines is
+// written in full. Every line of output counts towards the limit, including the lines written by WithField,
+// ShowCount, and StripCommonPrefix. This may be combined with MaxBytes, in which case the trace is cut off by whichever
+// limit is reached first. Reading with Read or ReadNext is not affected by this. Defaults to no limit.
+func MaxLines(n int) func(*Tracer) error {
+	return func(tracer *Tracer) error {
+		if n <= 0 {
+			return errors.New("maximum number of lines must be positive")
+		}
+
+		tracer.maxLines = n
 
 		return nil
 	}
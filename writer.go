@@ -0,0 +1,225 @@
+package xtrace
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+const truncationMarker = "... (truncated)"
+
+// truncatingWriter wraps an io.Writer, and will stop writing to it once a given number of bytes have been written,
+// writing the given marker in place of the remaining content.
+type truncatingWriter struct {
+	writer io.Writer
+	// Written in place of the content that was cut off
+	marker string
+	// The number of bytes that may still be written before truncating
+	remaining int
+	// Whether or not the output has been truncated
+	truncated bool
+}
+
+// Write implements io.Writer. Once the output has been truncated, all writes are discarded, but will still report that
+// the full contents were written.
+func (writer *truncatingWriter) Write(data []byte) (int, error) {
+	if writer.truncated {
+		return len(data), nil
+	} else if len(data) <= writer.remaining {
+		n, err := writer.writer.Write(data)
+		writer.remaining -= n
+
+		return n, err
+	}
+
+	// Make sure we don't cut a UTF-8 sequence in half
+	cutoff := writer.remaining
+	for cutoff > 0 && !utf8.RuneStart(data[cutoff]) {
+		cutoff--
+	}
+
+	writer.truncated = true
+	_, err := writer.writer.Write(data[:cutoff])
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = io.WriteString(writer.writer, writer.marker)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// lineTruncatingWriter wraps an io.Writer, and will stop writing to it once a given number of lines have been written,
+// writing the given marker in place of the remaining content.
+type lineTruncatingWriter struct {
+	writer io.Writer
+	// Written in place of the content that was cut off
+	marker string
+	// The number of newlines that may still be written before truncating
+	remaining int
+	// Whether or not the output has been truncated
+	truncated bool
+}
+
+// Write implements io.Writer. Once the output has been truncated, all writes are discarded, but will still report that
+// the full contents were written. The output is only truncated once there is content beyond the last line that may be
+// written, so output with exactly the maximum number of lines is written in full.
+func (writer *lineTruncatingWriter) Write(data []byte) (int, error) {
+	if writer.truncated || len(data) == 0 {
+		return len(data), nil
+	}
+
+	cutoff := 0
+	for writer.remaining > 0 && cutoff < len(data) {
+		lineEnd := bytes.IndexByte(data[cutoff:], '\n') + 1
+		if lineEnd == 0 {
+			cutoff = len(data)
+			break
+		}
+
+		cutoff += lineEnd
+		writer.remaining--
+	}
+
+	_, err := writer.writer.Write(data[:cutoff])
+	if err != nil {
+		return 0, err
+	} else if cutoff == len(data) {
+		return len(data), nil
+	}
+
+	writer.truncated = true
+	_, err = io.WriteString(writer.writer, writer.marker)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// isTruncated checks whether or not the given io.Writer, or any writer it wraps, has truncated its output.
+func isTruncated(writer io.Writer) bool {
+	for {
+		switch truncator := writer.(type) {
+		case *truncatingWriter:
+			if truncator.truncated {
+				return true
+			}
+
+			writer = truncator.writer
+		case *lineTruncatingWriter:
+			if truncator.truncated {
+				return true
+			}
+
+			writer = truncator.writer
+		case *lineEndingWriter:
+			writer = truncator.writer
+		default:
+			return false
+		}
+	}
+}
+
+// linePrefixWriter wraps an io.Writer, and will write the given prefix before each line written to it. The prefix is
+// only written once the line has content, so output that ends with a newline does not end with a dangling prefix.
+type linePrefixWriter struct {
+	writer io.Writer
+	prefix string
+	// Whether or not the next byte written starts a new line
+	midLine bool
+}
+
+// Write implements io.Writer. The prefixes that are written are not included in the returned count.
+func (writer *linePrefixWriter) Write(data []byte) (int, error) {
+	written := 0
+	for written < len(data) {
+		if !writer.midLine {
+			_, err := io.WriteString(writer.writer, writer.prefix)
+			if err != nil {
+				return written, err
+			}
+
+			writer.midLine = true
+		}
+
+		lineEnd := bytes.IndexByte(data[written:], '\n') + 1
+		if lineEnd == 0 {
+			lineEnd = len(data) - written
+		} else {
+			writer.midLine = false
+		}
+
+		n, err := writer.writer.Write(data[written : written+lineEnd])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// lineEndingWriter wraps an io.Writer, and will write each "\n" line ending written to it as the given line ending.
+// Line endings that are already "\r\n" are left as is.
+type lineEndingWriter struct {
+	writer io.Writer
+	ending string
+	// Whether or not the last byte written was a carriage return
+	afterCarriageReturn bool
+}
+
+// Write implements io.Writer. The returned count is that of the given data, not of the converted output.
+func (writer *lineEndingWriter) Write(data []byte) (int, error) {
+	converted := make([]byte, 0, len(data))
+	for _, b := range data {
+		if b == '\n' && !writer.afterCarriageReturn {
+			converted = append(converted, writer.ending...)
+		} else {
+			converted = append(converted, b)
+		}
+
+		writer.afterCarriageReturn = b == '\r'
+	}
+
+	_, err := writer.writer.Write(converted)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// countingWriter wraps an io.Writer, and keeps count of the number of bytes that have been written to it.
+type countingWriter struct {
+	writer io.Writer
+	// The number of bytes written to writer so far
+	count int64
+}
+
+// Write implements io.Writer.
+func (writer *countingWriter) Write(data []byte) (int, error) {
+	n, err := writer.writer.Write(data)
+	writer.count += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,102 @@
+// Package xtracetest provides helpers for asserting on the chains of errors in tests, using the Tracers of package
+// xtrace.
+package xtracetest
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ollien/xtrace"
+)
+
+// AssertChain asserts that the messages of the chain of the given error, from the root cause to the error itself,
+// contain each of the wanted strings, in order. The messages are those of a Tracer with detailed output disabled and
+// no other options, so file and line numbers, and the newlines that come with them, do not get in the way.
+//
+// Each wanted string must be a substring of a message, and the messages that contain them must appear in the same
+// order as the wanted strings, though not every message needs to be matched (e.g. []string{"things broke", "oh no"}
+// matches a chain of "things broke", "aw shucks", and "oh no"). Each message may only match a single wanted string.
+// If the assertion fails, the test is marked as failed with t.Errorf, and the messages of the chain are reported.
+// Returns whether or not the assertion passed.
+func AssertChain(t testing.TB, err error, want []string) bool {
+	t.Helper()
+
+	messages, traceErr := chainMessages(err)
+	if traceErr != nil {
+		t.Errorf("could not trace error: %s", traceErr)
+
+		return false
+	}
+
+	wantIndex := 0
+	for _, message := range messages {
+		if wantIndex < len(want) && strings.Contains(message, want[wantIndex]) {
+			wantIndex++
+		}
+	}
+
+	if wantIndex < len(want) {
+		t.Errorf(
+			"error chain does not contain %q in order (missing %q)\nchain: %s",
+			want,
+			want[wantIndex],
+			formatMessages(messages),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// chainMessages reads each of the messages of the chain of the given error, from the root cause to the error itself.
+func chainMessages(baseErr error) ([]string, error) {
+	tracer, err := xtrace.NewTracer(baseErr, xtrace.DetailedOutput(false), xtrace.Ordering(xtrace.OldestFirstOrdering))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []string{}
+	for {
+		message, err := tracer.ReadNext()
+		if err == io.EOF {
+			return messages, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+}
+
+// formatMessages formats the given messages for a failure report.
+func formatMessages(messages []string) string {
+	if len(messages) == 0 {
+		return "(empty)"
+	}
+
+	quoted := make([]string, len(messages))
+	for i, message := range messages {
+		quoted[i] = fmt.Sprintf("%q", message)
+	}
+
+	return strings.Join(quoted, " -> ")
+}
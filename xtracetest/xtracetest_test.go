@@ -0,0 +1,102 @@
+package xtracetest
+
+/*
+  Copyright 2019 Nicholas Krichevsky
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+// recordingTB is a testing.TB that records failures, rather than failing the test.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertChain(t *testing.T) {
+	err1 := errors.New("things broke :(")
+	err2 := xerrors.Errorf("aw shucks: %w", err1)
+	err3 := xerrors.Errorf("oh no: %w", err2)
+
+	tests := []struct {
+		name     string
+		err      error
+		want     []string
+		expected bool
+	}{
+		{
+			name:     "full chain",
+			err:      err3,
+			want:     []string{"things broke :(", "aw shucks", "oh no"},
+			expected: true,
+		},
+		{
+			name:     "substrings with gaps",
+			err:      err3,
+			want:     []string{"broke", "oh"},
+			expected: true,
+		},
+		{
+			name:     "nothing wanted",
+			err:      err3,
+			want:     nil,
+			expected: true,
+		},
+		{
+			name:     "out of order",
+			err:      err3,
+			want:     []string{"oh no", "things broke"},
+			expected: false,
+		},
+		{
+			name:     "message matched twice",
+			err:      err2,
+			want:     []string{"aw", "shucks"},
+			expected: false,
+		},
+		{
+			name:     "missing message",
+			err:      err2,
+			want:     []string{"things broke", "oh no"},
+			expected: false,
+		},
+		{
+			name:     "nil error",
+			err:      nil,
+			want:     []string{"things broke"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+			assert.Equal(t, tt.expected, AssertChain(tb, tt.err, tt.want))
+			assert.Equal(t, !tt.expected, len(tb.failures) > 0, tb.failures)
+		})
+	}
+}